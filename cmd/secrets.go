@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ananthakumaran/paisa/internal/secrets"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage encrypted credentials",
+	Long: `Manage encrypted credentials used by paisa.
+
+Secrets are encrypted with the passphrase in PAISA_SECRETS_PASSPHRASE
+if set, otherwise with a key file (paisa.key in the config directory,
+or PAISA_SECRETS_KEYFILE), which is generated on first use. A stored
+secret can be referred from kite.yaml as secret:<name>.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		InitConfig()
+	},
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Encrypt and store a secret, the value is read from stdin if not provided",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var value string
+		if len(args) == 2 {
+			value = args[1]
+		} else {
+			fmt.Fprintf(os.Stderr, "Enter value for %s: ", args[0])
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				log.Fatal(err)
+			}
+			value = strings.TrimRight(line, "\r\n")
+		}
+
+		err := secrets.Set(args[0], value)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("Stored %s, refer to it as %s%s\n", args[0], secrets.ReferencePrefix, args[0])
+	},
+}
+
+var secretsGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print the decrypted value of a secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		value, err := secrets.Get(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(value)
+	},
+}
+
+func init() {
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsGetCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ananthakumaran/paisa/internal/model"
 	"github.com/ananthakumaran/paisa/internal/server"
 	"github.com/ananthakumaran/paisa/internal/utils"
+	"github.com/ananthakumaran/paisa/internal/watcher"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -44,6 +45,12 @@ var serveCmd = &cobra.Command{
 			cancel()
 		}()
 
+		go func() {
+			if err := watcher.Watch(ctx, db); err != nil {
+				log.Warn("Failed to watch the journal for changes: ", err)
+			}
+		}()
+
 		// Start the server with context
 		if err := server.ListenWithContext(ctx, db, port); err != nil {
 			log.Fatal(err)
@@ -39,6 +39,7 @@ var updateCmd = &cobra.Command{
 
 		if syncAll {
 			model.SyncCII(db)
+			model.SyncCPI(db)
 		}
 	},
 }
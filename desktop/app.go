@@ -9,14 +9,17 @@ import (
 	"github.com/ananthakumaran/paisa/cmd"
 	"github.com/ananthakumaran/paisa/internal/background"
 	"github.com/ananthakumaran/paisa/internal/model"
+	"github.com/ananthakumaran/paisa/internal/notify"
 	"github.com/ananthakumaran/paisa/internal/utils"
+	"github.com/ananthakumaran/paisa/internal/watcher"
 	log "github.com/sirupsen/logrus"
 )
 
 // App struct
 type App struct {
-	ctx context.Context
-	db  gorm.DB
+	ctx         context.Context
+	db          gorm.DB
+	stopWatcher context.CancelFunc
 }
 
 // NewApp creates a new App application struct
@@ -40,15 +43,35 @@ func (a *App) startup(ctx context.Context) {
 
 	a.db = *db
 
+	notify.SetDesktopHandler(func(notification notify.Notification) error {
+		_, err := runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+			Type:    runtime.InfoDialog,
+			Title:   notification.Title,
+			Message: notification.Message,
+		})
+		return err
+	})
+
 	// Initialize and start background scheduler for desktop app
 	scheduler := background.GetScheduler()
 	scheduler.Initialize(db)
 	scheduler.Start()
+
+	watchCtx, stopWatcher := context.WithCancel(ctx)
+	a.stopWatcher = stopWatcher
+	go func() {
+		if err := watcher.Watch(watchCtx, db); err != nil {
+			log.Warn("Failed to watch the journal for changes: ", err)
+		}
+	}()
 }
 
 // shutdown is called when the app is about to close
 func (a *App) shutdown(ctx context.Context) {
 	log.Info("Desktop app shutting down, stopping background scheduler...")
+	if a.stopWatcher != nil {
+		a.stopWatcher()
+	}
 	scheduler := background.GetScheduler()
 	scheduler.Stop()
 	log.Info("Background scheduler stopped")
@@ -12,7 +12,10 @@ import (
 
 	"github.com/ananthakumaran/paisa/internal/background/kite"
 	"github.com/ananthakumaran/paisa/internal/background/prices"
+	"github.com/ananthakumaran/paisa/internal/calendar"
+	"github.com/ananthakumaran/paisa/internal/events"
 	"github.com/ananthakumaran/paisa/internal/model/task_execution"
+	"github.com/ananthakumaran/paisa/internal/notify"
 )
 
 type Scheduler struct {
@@ -35,6 +38,99 @@ type Task interface {
 	ShouldRunOnStartup() bool
 }
 
+// TradingDayTask is implemented by tasks that only make sense on the
+// days the exchange is open, like fetching the trades of the day.
+type TradingDayTask interface {
+	Task
+	Exchange() string
+}
+
+// CatchUpTask is implemented by tasks that can recover the schedule
+// windows missed while paisa was not running. CatchUp is called instead
+// of Run with the missed windows in ascending order, the last one being
+// the current window. Only the daily price update catches up, the Kite
+// trades api returns the trades of the current day alone, so the
+// trades of the missed days have to be imported from the tradebook.
+type CatchUpTask interface {
+	Task
+	CatchUp(ctx context.Context, db *gorm.DB, windows []time.Time) error
+}
+
+// maxCatchUpWindows limits how far back a task is asked to catch up
+const maxCatchUpWindows = 366
+
+// taskExchange returns the exchange whose trading calendar the task
+// follows, or an empty string if the task runs every day
+func taskExchange(task Task) string {
+	if t, ok := task.(TradingDayTask); ok {
+		return t.Exchange()
+	}
+	return ""
+}
+
+// missedWindows returns the schedule windows of the task between since
+// and now. Windows falling on days the exchange is closed are skipped.
+func missedWindows(task Task, since time.Time, now time.Time) ([]time.Time, error) {
+	if since.IsZero() {
+		return nil, nil
+	}
+
+	schedule, err := cron.ParseStandard(task.Schedule())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schedule %s: %w", task.Schedule(), err)
+	}
+
+	exchange := taskExchange(task)
+	var windows []time.Time
+	for next := schedule.Next(since.In(time.Local)); !next.After(now); next = schedule.Next(next) {
+		if exchange != "" && !calendar.IsTradingDay(exchange, next) {
+			continue
+		}
+		windows = append(windows, next)
+	}
+
+	if len(windows) > maxCatchUpWindows {
+		windows = windows[len(windows)-maxCatchUpWindows:]
+	}
+
+	return windows, nil
+}
+
+// runTask runs the task, handing over the missed windows to tasks that
+// support catch-up if more than one window was missed since the last
+// successful run.
+func (s *Scheduler) runTask(task Task) error {
+	if t, ok := task.(CatchUpTask); ok {
+		lastSuccessfulRun, err := task_execution.GetLastSuccessfulRun(s.db, task.Name())
+		if err != nil {
+			return err
+		}
+
+		windows, err := missedWindows(task, lastSuccessfulRun, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if len(windows) > 1 {
+			log.Infof("Catching up %d missed windows of task %s (%s to %s)",
+				len(windows), task.Name(), windows[0].Format("2006-01-02"), windows[len(windows)-1].Format("2006-01-02"))
+			return t.CatchUp(s.ctx, s.db, windows)
+		}
+	}
+
+	return task.Run(s.ctx, s.db)
+}
+
+func publishTaskResult(task Task, start time.Time, err error) {
+	data := events.TaskData{Name: task.Name(), Duration: time.Since(start).Seconds()}
+	if err != nil {
+		data.Error = err.Error()
+		events.Publish(events.TaskFailed, data)
+		return
+	}
+	events.Publish(events.TaskCompleted, data)
+}
+
 var (
 	scheduler *Scheduler
 	once      sync.Once
@@ -131,17 +227,25 @@ func (s *Scheduler) registerTask(task Task) {
 		s.wg.Add(1)
 		defer s.wg.Done()
 
+		if exchange := taskExchange(task); exchange != "" && !calendar.IsTradingDay(exchange, time.Now()) {
+			log.Infof("Skipping background task %s (%s is closed today)", task.Name(), exchange)
+			return
+		}
+
 		log.Infof("Starting background task: %s", task.Name())
 		start := time.Now()
+		events.Publish(events.TaskStarted, events.TaskData{Name: task.Name()})
 
 		// Update last run time before starting
 		if err := task_execution.UpdateLastRun(s.db, task.Name()); err != nil {
 			log.Errorf("Failed to update last run time for task %s: %v", task.Name(), err)
 		}
 
-		err := task.Run(s.ctx, s.db)
+		err := s.runTask(task)
+		publishTaskResult(task, start, err)
 		if err != nil {
 			log.Errorf("Background task %s failed: %v", task.Name(), err)
+			notify.Send(s.ctx, notify.EventTaskFailure, fmt.Sprintf("Background task %s failed", task.Name()), err.Error())
 		} else {
 			log.Infof("Background task %s completed in %v", task.Name(), time.Since(start))
 			// Update the last successful run time in database
@@ -176,7 +280,7 @@ func (s *Scheduler) runStartupTasks() {
 		}
 
 		// Check if task should run today
-		shouldRun, err := task_execution.ShouldRunToday(s.db, task.Name())
+		shouldRun, err := task_execution.ShouldRunToday(s.db, task.Name(), taskExchange(task))
 		if err != nil {
 			log.Errorf("Failed to check if task %s should run today: %v", task.Name(), err)
 			continue
@@ -188,14 +292,18 @@ func (s *Scheduler) runStartupTasks() {
 			go func(t Task) {
 				defer s.wg.Done()
 				start := time.Now()
+				events.Publish(events.TaskStarted, events.TaskData{Name: t.Name()})
 
 				// Update last run time before starting
 				if err := task_execution.UpdateLastRun(s.db, t.Name()); err != nil {
 					log.Errorf("Failed to update last run time for task %s: %v", t.Name(), err)
 				}
 
-				if err := t.Run(s.ctx, s.db); err != nil {
+				err := s.runTask(t)
+				publishTaskResult(t, start, err)
+				if err != nil {
 					log.Errorf("Failed to run startup task %s: %v", t.Name(), err)
+					notify.Send(s.ctx, notify.EventTaskFailure, fmt.Sprintf("Background task %s failed", t.Name()), err.Error())
 				} else {
 					log.Infof("Startup task %s completed in %v", t.Name(), time.Since(start))
 					// Update the last successful run time in database
@@ -205,7 +313,7 @@ func (s *Scheduler) runStartupTasks() {
 				}
 			}(task)
 		} else {
-			log.Infof("Skipping startup task %s (already run successfully today)", task.Name())
+			log.Infof("Skipping startup task %s (already run successfully since the last scheduled day)", task.Name())
 		}
 	}
 }
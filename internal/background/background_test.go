@@ -0,0 +1,33 @@
+package background
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/background/kite"
+	"github.com/ananthakumaran/paisa/internal/background/prices"
+	"github.com/stretchr/testify/assert"
+)
+
+func at(s string) time.Time {
+	t, _ := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+	return t
+}
+
+func TestMissedWindows(t *testing.T) {
+	windows, err := missedWindows(&prices.DailyPriceUpdateTask{}, time.Time{}, at("2025-03-20 19:00"))
+	assert.NoError(t, err)
+	assert.Empty(t, windows, "never run before")
+
+	windows, err = missedWindows(&prices.DailyPriceUpdateTask{}, at("2025-03-20 18:05"), at("2025-03-20 19:00"))
+	assert.NoError(t, err)
+	assert.Empty(t, windows)
+
+	windows, err = missedWindows(&prices.DailyPriceUpdateTask{}, at("2025-03-12 18:05"), at("2025-03-17 19:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{at("2025-03-13 18:00"), at("2025-03-14 18:00"), at("2025-03-15 18:00"), at("2025-03-16 18:00"), at("2025-03-17 18:00")}, windows)
+
+	windows, err = missedWindows(&kite.DailyTradesTask{}, at("2025-03-12 16:05"), at("2025-03-17 17:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{at("2025-03-13 16:00"), at("2025-03-17 16:00")}, windows, "skips holi and the weekend")
+}
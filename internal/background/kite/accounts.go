@@ -0,0 +1,135 @@
+package kite
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/model"
+)
+
+const (
+	TokenStateValid   = "valid"
+	TokenStateExpired = "expired"
+	TokenStateMissing = "missing"
+)
+
+// AccountStatus describes the state of the access token of an account
+type AccountStatus struct {
+	Name        string    `json:"name"`
+	State       string    `json:"state"`
+	LastLoginAt time.Time `json:"last_login_time"`
+	ExpiresAt   time.Time `json:"expiry_time"`
+	LastError   string    `json:"last_error"`
+}
+
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+// tokenExpiry returns the time at which an access token issued at the
+// given time expires. Kite invalidates all access tokens at 6 AM IST.
+func tokenExpiry(issuedAt time.Time) time.Time {
+	issuedAt = issuedAt.In(ist)
+	expiry := time.Date(issuedAt.Year(), issuedAt.Month(), issuedAt.Day(), 6, 0, 0, 0, ist)
+	if !expiry.After(issuedAt) {
+		expiry = expiry.AddDate(0, 0, 1)
+	}
+	return expiry
+}
+
+// GetAccountStatuses returns the token state of all the configured
+// accounts. The state is derived from the stored token, no request is
+// made to Kite. The list is empty if Kite is not configured.
+func GetAccountStatuses(db *gorm.DB) ([]AccountStatus, error) {
+	kiteConfig, err := readKiteConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []AccountStatus{}
+	if kiteConfig == nil {
+		return statuses, nil
+	}
+
+	for _, account := range kiteConfig.Accounts {
+		status := AccountStatus{Name: account.Name, State: TokenStateMissing}
+
+		auth, err := model.GetAuthByAPIKey(db, account.APIKey)
+		if err != nil {
+			status.LastError = err.Error()
+			statuses = append(statuses, status)
+			continue
+		}
+
+		if auth != nil {
+			status.LastError = auth.LastError
+			if auth.AccessToken != "" && !auth.AccessTokenAt.IsZero() {
+				status.LastLoginAt = auth.AccessTokenAt
+				status.ExpiresAt = tokenExpiry(auth.AccessTokenAt)
+				if time.Now().Before(status.ExpiresAt) {
+					status.State = TokenStateValid
+				} else {
+					status.State = TokenStateExpired
+				}
+			}
+		}
+
+		// the login fails without the credentials
+		if err := account.resolveSecrets(); err != nil {
+			status.LastError = err.Error()
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func findAccountByName(name string) (*KiteAccount, error) {
+	kiteConfig, err := loadKiteConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load KITE config: %w", err)
+	}
+
+	for i := range kiteConfig.Accounts {
+		if kiteConfig.Accounts[i].Name == name {
+			return &kiteConfig.Accounts[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no account found with name: %s", name)
+}
+
+// GetLoginURL returns the Kite login URL of the named account
+func GetLoginURL(name string) (string, error) {
+	account, err := findAccountByName(name)
+	if err != nil {
+		return "", err
+	}
+
+	return LoginURL(account), nil
+}
+
+// CompleteLogin stores the request token received on the login callback
+// and exchanges it for an access token.
+func CompleteLogin(db *gorm.DB, name string, requestToken string) error {
+	account, err := findAccountByName(name)
+	if err != nil {
+		return err
+	}
+
+	err = model.StoreRequestToken(db, account.APIKey, requestToken)
+	if err != nil {
+		return fmt.Errorf("failed to store request token: %w", err)
+	}
+
+	accessToken, err := FetchAccessTokenFromRequestToken(account.APIKey, requestToken)
+	if err != nil {
+		if storeErr := model.StoreAuthError(db, account.APIKey, err.Error()); storeErr != nil {
+			return storeErr
+		}
+		return err
+	}
+
+	return model.UpdateAccessToken(db, account.APIKey, accessToken)
+}
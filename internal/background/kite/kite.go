@@ -54,6 +54,8 @@ type Trade struct {
 	ExchangeTimestamp KiteTime        `json:"exchange_timestamp"`
 }
 
+// DailyTradesTask doesn't support catch-up, Kite Connect only returns
+// the trades of the current day
 type DailyTradesTask struct{}
 
 func (t *DailyTradesTask) Name() string {
@@ -68,6 +70,12 @@ func (t *DailyTradesTask) ShouldRunOnStartup() bool {
 	return true
 }
 
+// Exchange restricts the task to NSE trading days, there are no trades
+// to fetch on weekends and exchange holidays.
+func (t *DailyTradesTask) Exchange() string {
+	return "NSE"
+}
+
 func (t *DailyTradesTask) Run(ctx context.Context, db *gorm.DB) error {
 	log.Info("Starting daily trades fetch from KITE Connect for all accounts")
 
@@ -96,7 +104,6 @@ func (t *DailyTradesTask) Run(ctx context.Context, db *gorm.DB) error {
 
 		// Fetch trades for today for this account
 		trades, err := fetchDailyTrades(ctx, account.APIKey, accessToken)
-		log.Infof("Fetched %d trades for account %s %s", len(trades), account.APIKey, accessToken)
 		if err != nil {
 			log.Warnf("Failed to fetch daily trades for account %s: %v", account.Name, err)
 			continue // Continue with other accounts even if one fails
@@ -160,8 +167,29 @@ func loadKiteConfig() (*KiteConfig, error) {
 		return nil, fmt.Errorf("KITE config file created, please update with your credentials")
 	}
 
-	// Read existing config
+	kiteConfig, err := readKiteConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range kiteConfig.Accounts {
+		err = kiteConfig.Accounts[i].resolveSecrets()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return kiteConfig, nil
+}
+
+// readKiteConfig reads the KITE config without resolving the secrets,
+// it returns nil if the config file doesn't exist
+func readKiteConfig() (*KiteConfig, error) {
+	kiteConfigPath := filepath.Join(config.GetConfigDir(), "kite.yaml")
 	configData, err := os.ReadFile(kiteConfigPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read KITE config file: %w", err)
 	}
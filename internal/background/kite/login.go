@@ -13,6 +13,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/ananthakumaran/paisa/internal/model"
+	"github.com/ananthakumaran/paisa/internal/secrets"
 	"github.com/ananthakumaran/paisa/internal/utils"
 	"github.com/pquerna/otp/totp"
 	log "github.com/sirupsen/logrus"
@@ -36,6 +37,19 @@ type KiteAccount struct {
 	TOTPToken string `json:"totp_token" yaml:"totp_token"`
 }
 
+// resolveSecrets replaces the credentials that refer to the secrets
+// store or are encrypted inline with their plaintext values
+func (a *KiteAccount) resolveSecrets() error {
+	for _, field := range []*string{&a.APISecret, &a.Password, &a.TOTPToken} {
+		value, err := secrets.Resolve(*field)
+		if err != nil {
+			return fmt.Errorf("failed to resolve credentials of account %s: %w", a.Name, err)
+		}
+		*field = value
+	}
+	return nil
+}
+
 // KiteConfig holds the configuration for multiple KITE Connect accounts
 type KiteConfig struct {
 	Accounts []KiteAccount `json:"accounts" yaml:"accounts"`
@@ -103,9 +117,8 @@ func LoginAndStoreTokenForAPIKey(db *gorm.DB, apiKey string) error {
 	} else {
 		log.Errorf("Failed to login with web flow for account %s: %v", targetAccount.Name, err)
 		DoManualLogin(targetAccount)
+		return fmt.Errorf("automatic login failed, login manually from the Kite accounts page: %w", err)
 	}
-
-	return nil
 }
 
 // FetchAccessTokenFromRequestToken gets an access token from a request token for a specific API key.
@@ -181,16 +194,22 @@ func FetchAccessTokenFromRequestToken(apiKey string, requestToken string) (strin
 	}
 
 	accessToken := sessionResponse.Data.AccessToken
-	log.Infof("Successfully got access token for account %s: %s", targetAccount.Name, accessToken)
+	log.Infof("Successfully got access token for account %s", targetAccount.Name)
 
 	return accessToken, nil
 }
 
-func DoManualLogin(account *KiteAccount) {
+// LoginURL returns the Kite Connect login URL of the account. The account
+// name is passed along as a redirect param, so that the callback knows
+// which account the request token belongs to.
+func LoginURL(account *KiteAccount) string {
 	kc := kiteconnect.New(account.APIKey)
+	return kc.GetLoginURLWithparams(url.Values{"account": {account.Name}})
+}
 
+func DoManualLogin(account *KiteAccount) {
 	// Get the login URL
-	kiteLoginURL := kc.GetLoginURL()
+	kiteLoginURL := LoginURL(account)
 	log.Infof("--------------------------------")
 	log.Info("Please login to Kite Connect by visiting the below URL:")
 	log.Info(kiteLoginURL)
@@ -420,7 +439,7 @@ func DoAutoLogin(account *KiteAccount) (string, error) {
 	}
 
 	requestToken := matches[1]
-	log.Infof("Got request token from final redirect: %s", requestToken)
+	log.Info("Got request token from final redirect")
 
 	return requestToken, nil
 }
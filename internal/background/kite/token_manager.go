@@ -1,6 +1,7 @@
 package kite
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,10 +12,24 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/ananthakumaran/paisa/internal/model"
+	"github.com/ananthakumaran/paisa/internal/notify"
 )
 
 // GetValidAccessToken returns a valid access token from the database for a specific API key. If the existing access token is expired, it will be refreshed.
+// Failures are recorded against the API key so that they can be shown in the UI.
 func GetValidAccessToken(db *gorm.DB, apiKey string) (string, error) {
+	accessToken, err := getValidAccessToken(db, apiKey)
+	if err != nil {
+		if storeErr := model.StoreAuthError(db, apiKey, err.Error()); storeErr != nil {
+			log.Errorf("Failed to store auth error for API key %s: %v", apiKey, storeErr)
+		}
+		notify.Send(context.Background(), notify.EventKiteLogin, "Kite login required", fmt.Sprintf("Failed to get a valid access token for API key %s: %v", apiKey, err))
+		return "", err
+	}
+	return accessToken, nil
+}
+
+func getValidAccessToken(db *gorm.DB, apiKey string) (string, error) {
 	// Get the current authentication data from the database for this API key
 	auth, err := model.GetAuthByAPIKey(db, apiKey)
 	if err != nil {
@@ -2,6 +2,7 @@ package prices
 
 import (
 	"context"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -40,6 +41,12 @@ func (t *DailyPriceUpdateTask) Run(ctx context.Context, db *gorm.DB) error {
 		// Don't fail the entire task for CII sync failure
 	}
 
+	// Update CPI (Consumer Price Index) for the inflation adjusted amounts
+	err = model.SyncCPI(db)
+	if err != nil {
+		log.Warnf("Failed to sync CPI: %v", err)
+	}
+
 	// Update mutual fund portfolios
 	err = model.SyncPortfolios(db)
 	if err != nil {
@@ -50,3 +57,11 @@ func (t *DailyPriceUpdateTask) Run(ctx context.Context, db *gorm.DB) error {
 	log.Info("Daily price update completed successfully")
 	return nil
 }
+
+// CatchUp runs the price update once for all the missed windows. The
+// prices are fetched since the last stored price of each commodity,
+// which covers the gap.
+func (t *DailyPriceUpdateTask) CatchUp(ctx context.Context, db *gorm.DB, windows []time.Time) error {
+	log.Infof("Backfilling prices missed since %s", windows[0].Format("2006-01-02"))
+	return t.Run(ctx, db)
+}
@@ -0,0 +1,110 @@
+package calendar
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed holidays.json
+var holidaysJson string
+
+// exchanges that follow the trading calendar of another exchange
+var aliases = map[string]string{
+	"BSE": "NSE",
+}
+
+type bundledHolidays struct {
+	sync.Once
+	holidays map[string]map[string]string
+}
+
+var bundled bundledHolidays
+
+func loadBundledHolidays() {
+	type entry struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	}
+
+	var entries map[string][]entry
+	err := json.Unmarshal([]byte(holidaysJson), &entries)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bundled.holidays = make(map[string]map[string]string)
+	for exchange, es := range entries {
+		bundled.holidays[exchange] = make(map[string]string)
+		for _, e := range es {
+			bundled.holidays[exchange][e.Date] = e.Name
+		}
+	}
+}
+
+func normalize(exchange string) string {
+	exchange = strings.ToUpper(strings.TrimSpace(exchange))
+	if alias, ok := aliases[exchange]; ok {
+		return alias
+	}
+	return exchange
+}
+
+// holidays returns the bundled holidays of the exchange merged with the
+// ones configured by the user, keyed by date in YYYY-MM-DD format.
+func holidays(exchange string) map[string]string {
+	bundled.Do(loadBundledHolidays)
+
+	exchange = normalize(exchange)
+	result := make(map[string]string)
+	for date, name := range bundled.holidays[exchange] {
+		result[date] = name
+	}
+
+	for _, h := range config.GetConfig().MarketHolidays {
+		if normalize(h.Exchange) == exchange {
+			result[h.Date] = h.Name
+		}
+	}
+
+	return result
+}
+
+func IsHoliday(exchange string, date time.Time) bool {
+	_, found := holidays(exchange)[date.In(config.TimeZone()).Format("2006-01-02")]
+	return found
+}
+
+// IsTradingDay returns true if the exchange is open on the given date,
+// weekends are always considered closed.
+func IsTradingDay(exchange string, date time.Time) bool {
+	date = date.In(config.TimeZone())
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+
+	return !IsHoliday(exchange, date)
+}
+
+// LastTradingDay returns the beginning of the most recent trading day on
+// or before the given date.
+func LastTradingDay(exchange string, date time.Time) time.Time {
+	date = date.In(config.TimeZone())
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	// an exchange is never closed for more than a few weeks in a row, the
+	// limit only guards against a misconfigured holiday list
+	for i := 0; i < 366; i++ {
+		if IsTradingDay(exchange, day) {
+			return day
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return day
+}
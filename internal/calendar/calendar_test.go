@@ -0,0 +1,29 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, _ := time.ParseInLocation("2006-01-02", s, config.TimeZone())
+	return t
+}
+
+func TestIsTradingDay(t *testing.T) {
+	assert.True(t, IsTradingDay("NSE", date("2025-03-13")))
+	assert.False(t, IsTradingDay("NSE", date("2025-03-14")), "holi")
+	assert.False(t, IsTradingDay("NSE", date("2025-03-15")), "saturday")
+	assert.False(t, IsTradingDay("NSE", date("2025-03-16")), "sunday")
+	assert.False(t, IsTradingDay("bse", date("2025-03-14")), "bse follows nse")
+	assert.True(t, IsTradingDay("NYSE", date("2025-03-14")), "unknown exchange has only weekends")
+}
+
+func TestLastTradingDay(t *testing.T) {
+	assert.Equal(t, date("2025-03-13"), LastTradingDay("NSE", date("2025-03-16").Add(10*time.Hour)))
+	assert.Equal(t, date("2025-03-17"), LastTradingDay("NSE", date("2025-03-17")))
+	assert.Equal(t, date("2025-10-20"), LastTradingDay("NSE", date("2025-10-22")))
+}
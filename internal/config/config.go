@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -36,6 +37,8 @@ const (
 	NPS        CommodityType = "nps"
 	Stock      CommodityType = "stock"
 	Metal      CommodityType = "metal"
+	Currency   CommodityType = "currency"
+	Crypto     CommodityType = "crypto"
 	Unknown    CommodityType = "unknown"
 )
 
@@ -105,6 +108,16 @@ type ScheduleAL struct {
 	Accounts []string `json:"accounts" yaml:"accounts"`
 }
 
+type FX struct {
+	Source   string   `json:"source" yaml:"source"`
+	AutoFill BoolType `json:"auto_fill" yaml:"auto_fill"`
+}
+
+type CPI struct {
+	Source  string `json:"source" yaml:"source"`
+	Country string `json:"country" yaml:"country"`
+}
+
 type Budget struct {
 	Rollover BoolType `json:"rollover" yaml:"rollover"`
 }
@@ -125,6 +138,54 @@ type CreditCard struct {
 	ExpirationDate  string `json:"expiration_date" yaml:"expiration_date"`
 }
 
+type LoanRateChange struct {
+	Date string  `json:"date" yaml:"date"`
+	Rate float64 `json:"rate" yaml:"rate"`
+}
+
+type Loan struct {
+	Name            string           `json:"name" yaml:"name"`
+	Account         string           `json:"account" yaml:"account"`
+	InterestAccount string           `json:"interest_account" yaml:"interest_account"`
+	PaymentAccount  string           `json:"payment_account" yaml:"payment_account"`
+	Principal       float64          `json:"principal" yaml:"principal"`
+	Rate            float64          `json:"rate" yaml:"rate"`
+	Tenure          int              `json:"tenure" yaml:"tenure"`
+	StartDate       string           `json:"start_date" yaml:"start_date"`
+	EMI             float64          `json:"emi" yaml:"emi"`
+	RateChanges     []LoanRateChange `json:"rate_changes" yaml:"rate_changes"`
+}
+
+type MarketHoliday struct {
+	Exchange string `json:"exchange" yaml:"exchange"`
+	Date     string `json:"date" yaml:"date"`
+	Name     string `json:"name" yaml:"name"`
+}
+
+type Notifier struct {
+	Name     string   `json:"name" yaml:"name"`
+	Type     string   `json:"type" yaml:"type"`
+	Events   []string `json:"events" yaml:"events"`
+	URL      string   `json:"url" yaml:"url"`
+	Token    string   `json:"token" yaml:"token"`
+	Host     string   `json:"host" yaml:"host"`
+	Port     int      `json:"port" yaml:"port"`
+	Username string   `json:"username" yaml:"username"`
+	Password string   `json:"password" yaml:"password"`
+	From     string   `json:"from" yaml:"from"`
+	To       []string `json:"to" yaml:"to"`
+}
+
+type DoctorRule struct {
+	Name        string  `json:"name" yaml:"name"`
+	Description string  `json:"description" yaml:"description"`
+	Level       string  `json:"level" yaml:"level"`
+	Account     string  `json:"account" yaml:"account"`
+	Check       string  `json:"check" yaml:"check"`
+	Operator    string  `json:"operator" yaml:"operator"`
+	Value       float64 `json:"value" yaml:"value"`
+}
+
 type Config struct {
 	JournalPath                string       `json:"journal_path" yaml:"journal_path"`
 	DBPath                     string       `json:"db_path" yaml:"db_path"`
@@ -142,6 +203,10 @@ type Config struct {
 
 	Budget Budget `json:"budget" yaml:"budget"`
 
+	FX FX `json:"fx" yaml:"fx"`
+
+	CPI CPI `json:"cpi" yaml:"cpi"`
+
 	ScheduleALs []ScheduleAL `json:"schedule_al" yaml:"schedule_al"`
 
 	AllocationTargets []AllocationTarget `json:"allocation_targets" yaml:"allocation_targets"`
@@ -157,6 +222,14 @@ type Config struct {
 	UserAccounts []UserAccount `json:"user_accounts" yaml:"user_accounts"`
 
 	CreditCards []CreditCard `json:"credit_cards" yaml:"credit_cards"`
+
+	Loans []Loan `json:"loans" yaml:"loans"`
+
+	MarketHolidays []MarketHoliday `json:"market_holidays" yaml:"market_holidays"`
+
+	Notifiers []Notifier `json:"notifiers" yaml:"notifiers"`
+
+	DoctorRules []DoctorRule `json:"doctor_rules" yaml:"doctor_rules"`
 }
 
 var config Config
@@ -172,6 +245,8 @@ var defaultConfig = Config{
 	Locale:                     "en-IN",
 	TimeZone:                   "",
 	Budget:                     Budget{Rollover: Yes},
+	FX:                         FX{Source: "frankfurter", AutoFill: No},
+	CPI:                        CPI{Source: "worldbank", Country: "IND"},
 	FinancialYearStartingMonth: 4,
 	Strict:                     No,
 	WeekStartingDay:            0,
@@ -183,6 +258,10 @@ var defaultConfig = Config{
 	Goals:                      Goals{Retirement: []RetirementGoal{}, Savings: []SavingsGoal{}},
 	UserAccounts:               []UserAccount{},
 	CreditCards:                []CreditCard{},
+	Loans:                      []Loan{},
+	MarketHolidays:             []MarketHoliday{},
+	Notifiers:                  []Notifier{},
+	DoctorRules:                []DoctorRule{},
 }
 
 var itemsUniquePropertiesMeta = jsonschema.MustCompileString("itemsUniqueProperties.json", `{
@@ -296,6 +375,28 @@ func LoadConfigFile(path string) {
 	log.Info("Using config file: ", path)
 }
 
+// priceProviders are the codes of the available price providers, the
+// providers are registered by the scraper package to avoid an import
+// cycle
+var priceProviders []string
+
+func RegisterPriceProviders(codes []string) {
+	priceProviders = codes
+}
+
+func validatePriceProviders(commodities []Commodity) error {
+	if len(priceProviders) == 0 {
+		return nil
+	}
+
+	for _, commodity := range commodities {
+		if !slices.Contains(priceProviders, commodity.Price.Provider) {
+			return fmt.Errorf("Unknown price provider %s for commodity %s, should be one of %s", commodity.Price.Provider, commodity.Name, strings.Join(priceProviders, ", "))
+		}
+	}
+	return nil
+}
+
 func LoadConfig(content []byte, cp string) error {
 	var configJson interface{}
 	err := yaml.Unmarshal(content, &configJson)
@@ -320,6 +421,11 @@ func LoadConfig(content []byte, cp string) error {
 		return err
 	}
 
+	err = validatePriceProviders(config.Commodities)
+	if err != nil {
+		return err
+	}
+
 	if cp != "" && configPath == "" {
 		configPath = cp
 	}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePriceProviders(t *testing.T) {
+	RegisterPriceProviders([]string{"com-yahoo", "in-mfapi"})
+	defer RegisterPriceProviders(nil)
+
+	assert.NoError(t, validatePriceProviders([]Commodity{{Name: "AAPL", Price: Price{Provider: "com-yahoo", Code: "AAPL"}}}))
+
+	err := validatePriceProviders([]Commodity{{Name: "AAPL", Price: Price{Provider: "com-yaho", Code: "AAPL"}}})
+	assert.ErrorContains(t, err, "Unknown price provider com-yaho for commodity AAPL")
+}
+
+func TestDefaultConfigRoundTrip(t *testing.T) {
+	err := LoadConfig([]byte("journal_path: main.ledger\ndb_path: paisa.db\n"), "")
+	assert.NoError(t, err)
+
+	// the config is saved from the UI as returned by /api/config
+	content, err := json.Marshal(GetConfig())
+	assert.NoError(t, err)
+	assert.NoError(t, LoadConfig(content, ""))
+}
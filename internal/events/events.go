@@ -0,0 +1,92 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	JournalSynced = "journal_synced"
+	FileSaved     = "file_saved"
+	TaskStarted   = "task_started"
+	TaskCompleted = "task_completed"
+	TaskFailed    = "task_failed"
+	AlertRaised   = "alert_raised"
+)
+
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data,omitempty"`
+}
+
+// JournalSyncData is published with JournalSynced, Files are the
+// journal files that changed since the last sync
+type JournalSyncData struct {
+	Files   []string `json:"files"`
+	Success bool     `json:"success"`
+	Message string   `json:"message,omitempty"`
+}
+
+// FileSaveData is published with FileSaved when a journal file is
+// saved from the editor
+type FileSaveData struct {
+	Name string `json:"name"`
+}
+
+// TaskData is published with the background task events
+type TaskData struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// AlertData is published with AlertRaised for every notification sent,
+// like the price alerts and the task failures
+type AlertData struct {
+	Event   string `json:"event"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+var (
+	subscribers   = make(map[chan Event]struct{})
+	subscribersMu sync.Mutex
+)
+
+// Subscribe returns a channel which receives all the published events
+// and a function to stop the subscription
+func Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+
+	subscribersMu.Lock()
+	subscribers[ch] = struct{}{}
+	subscribersMu.Unlock()
+
+	return ch, func() {
+		subscribersMu.Lock()
+		defer subscribersMu.Unlock()
+		if _, ok := subscribers[ch]; ok {
+			delete(subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish sends the event to all the subscribers, slow subscribers
+// miss the event instead of blocking the publisher
+func Publish(eventType string, data any) {
+	event := Event{Type: eventType, Time: time.Now(), Data: data}
+
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	for ch := range subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Warnf("Dropping %s event for a slow subscriber", eventType)
+		}
+	}
+}
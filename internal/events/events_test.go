@@ -0,0 +1,22 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	ch, unsubscribe := Subscribe()
+
+	Publish(JournalSynced, JournalSyncData{Files: []string{"main.ledger"}, Success: true})
+	event := <-ch
+	assert.Equal(t, JournalSynced, event.Type)
+	assert.Equal(t, JournalSyncData{Files: []string{"main.ledger"}, Success: true}, event.Data)
+
+	unsubscribe()
+	unsubscribe()
+	Publish(JournalSynced, nil)
+	_, ok := <-ch
+	assert.False(t, ok)
+}
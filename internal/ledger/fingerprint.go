@@ -0,0 +1,120 @@
+package ledger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/utils"
+)
+
+// ContextFile is the fingerprint key for everything that affects the
+// parsing of all the files: the config, the directives like alias,
+// account, price, periodic transactions etc. Budget and forecast
+// postings which don't belong to any file use the same key as their
+// file name.
+const ContextFile = ""
+
+var includeRegex = regexp.MustCompile(`^[!@]?include\s+(.+)$`)
+
+// beancount directives like open, pad and balance are dated like the
+// transactions but affect the other files
+var datedDirectiveRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\s+(open|close|commodity|pad|balance|price|note|document|event|query|custom)\b`)
+
+// Fingerprint returns the content hash of the journal and all the
+// included files, keyed by the path relative to the journal directory.
+func Fingerprint(journalPath string) (map[string]string, error) {
+	dir := filepath.Dir(config.GetJournalPath())
+	hashes := make(map[string]string)
+
+	context := sha256.New()
+	cfg := config.GetConfig()
+	fmt.Fprintf(context, "%s\n%s\n%s\n%s\n%s\n", cfg.LedgerCli, config.DefaultCurrency(), cfg.Strict, config.TimeZone().String(), utils.Now().Format("2006-01-02"))
+
+	visited := make(map[string]bool)
+	var visit func(path string) error
+	visit = func(path string) error {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+
+		if visited[absPath] {
+			return nil
+		}
+		visited[absPath] = true
+
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			return err
+		}
+
+		fileName, err := filepath.Rel(dir, absPath)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(content)
+		hashes[fileName] = hex.EncodeToString(sum[:])
+
+		fmt.Fprintf(context, "#%s\n", fileName)
+		directive := false
+		for _, line := range strings.Split(utils.Dos2Unix(string(content)), "\n") {
+			line = strings.TrimRight(line, " \t")
+			if line == "" {
+				directive = false
+				continue
+			}
+
+			if line[0] == ' ' || line[0] == '\t' {
+				if directive {
+					fmt.Fprintln(context, line)
+				}
+				continue
+			}
+
+			directive = datedDirectiveRegex.MatchString(line) || !(line[0] >= '0' && line[0] <= '9') && !isNativeComment(line)
+			if !directive {
+				continue
+			}
+			fmt.Fprintln(context, line)
+
+			match := includeRegex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+
+			pattern, _ := splitNativeComment(match[1])
+			pattern = utils.UnQuote(pattern)
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join(filepath.Dir(absPath), pattern)
+			}
+
+			// missing includes are reported by the validation
+			matches, _ := filepath.Glob(pattern)
+			sort.Strings(matches)
+			for _, match := range matches {
+				err := visit(match)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	err := visit(journalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes[ContextFile] = hex.EncodeToString(context.Sum(nil))
+	return hashes, nil
+}
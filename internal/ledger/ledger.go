@@ -39,6 +39,23 @@ type Ledger interface {
 	Prices(jornalPath string) ([]price.Price, error)
 }
 
+// IncrementalLedger is implemented by the ledgers that can skip
+// building the postings of the files that have not changed since the
+// last sync. The journal is still parsed as a whole, the directives of
+// any file can change the postings of the others.
+type IncrementalLedger interface {
+	ParseFiles(journalPath string, prices []price.Price, fileNames []string) ([]*posting.Posting, error)
+}
+
+// HasFileStableIDs reports whether the transaction ids of a file stay
+// the same when the other files change, the postings of the unchanged
+// files can be reused only then. hledger numbers the transactions
+// across the whole journal.
+func HasFileStableIDs(l Ledger) bool {
+	_, ok := l.(HLedgerCLI)
+	return !ok
+}
+
 type LedgerCLI struct{}
 type HLedgerCLI struct{}
 type Beancount struct{}
@@ -52,6 +69,10 @@ func Cli() Ledger {
 		return Beancount{}
 	}
 
+	if config.GetConfig().LedgerCli == "native" {
+		return Native{}
+	}
+
 	return LedgerCLI{}
 }
 
@@ -282,10 +303,11 @@ func (Beancount) Parse(journalPath string, prices []price.Price) ([]*posting.Pos
 		Location
 		TransactionID
 		Status
+		PostingStatus
 		TagRecurring
 		TagPeriod
 	)
-	args := []string{"-f", "csv", journalPath, "select date,payee,narration,account,currency,units(position),cost(position),filename,location,id,flag,ANY_META('recurring'),ANY_META('period')"}
+	args := []string{"-f", "csv", journalPath, "select date,payee,narration,account,currency,units(position),cost(position),filename,location,id,flag,posting_flag,ANY_META('recurring'),ANY_META('period')"}
 
 	path, err := binary.LookPath("bean-query")
 	if err != nil {
@@ -343,14 +365,7 @@ func (Beancount) Parse(journalPath string, prices []price.Price) ([]*posting.Pos
 			payee += narration
 		}
 
-		var status string
-		if record[Status] == "*" {
-			status = "cleared"
-		} else if record[Status] == "!" {
-			status = "pending"
-		} else {
-			status = "unmarked"
-		}
+		status := flagStatus(record[PostingStatus], record[Status])
 
 		match := locationRegex.FindStringSubmatch(strings.TrimSpace(record[Location]))
 		if len(match) == 0 {
@@ -656,14 +671,7 @@ func execLedgerCommand(journalPath string, flags []string) ([]*posting.Posting,
 			forecast = false
 		}
 
-		var status string
-		if record[Status] == "*" {
-			status = "cleared"
-		} else if record[Status] == "!" {
-			status = "pending"
-		} else {
-			status = "unmarked"
-		}
+		status := flagStatus(record[Status])
 
 		transactionBeginLine, err := strconv.ParseUint(record[TransactionBeginLine], 10, 64)
 		if err != nil {
@@ -717,6 +725,7 @@ func execLedgerCommand(journalPath string, flags []string) ([]*posting.Posting,
 
 type HLedgerPosting struct {
 	Account string     `json:"paccount"`
+	Status  string     `json:"pstatus"`
 	Comment string     `json:"pcomment"`
 	Tags    [][]string `json:"ptags"`
 	Amount  []struct {
@@ -888,7 +897,7 @@ func buildHLedgerPostings(p HLedgerPosting, t HLedgerTransaction, pricesTree map
 			Quantity:             decimal.NewFromFloat(amount.Quantity.Value),
 			Amount:               totalAmount,
 			TransactionID:        strconv.FormatInt(t.ID, 10),
-			Status:               strings.ToLower(t.Status),
+			Status:               hledgerStatus(p.Status, t.Status),
 			TagRecurring:         tagRecurring,
 			TagPeriod:            tagPeriod,
 			TransactionBeginLine: t.TSourcePos[0].SourceLine,
@@ -903,6 +912,30 @@ func buildHLedgerPostings(p HLedgerPosting, t HLedgerTransaction, pricesTree map
 	return postings, nil
 }
 
+// flagStatus maps the first * or ! flag to the posting status. The
+// posting flag is passed before the transaction flag so that it takes
+// precedence.
+func flagStatus(flags ...string) string {
+	for _, flag := range flags {
+		switch strings.TrimSpace(flag) {
+		case "*":
+			return "cleared"
+		case "!":
+			return "pending"
+		}
+	}
+	return "unmarked"
+}
+
+// hledgerStatus prefers the posting status over the transaction status,
+// hledger reports Unmarked for the postings without one.
+func hledgerStatus(postingStatus string, transactionStatus string) string {
+	if postingStatus != "" && postingStatus != "Unmarked" {
+		return strings.ToLower(postingStatus)
+	}
+	return strings.ToLower(transactionStatus)
+}
+
 func buildPricesTree(prices []price.Price) map[string]*btree.BTree {
 	pricesTree := make(map[string]*btree.BTree)
 	for _, price := range prices {
@@ -1,6 +1,7 @@
 package ledger
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/ananthakumaran/paisa/internal/model/price"
@@ -110,3 +111,38 @@ func TestParseAmount(t *testing.T) {
 	assert.Equal(t, "BTC", commodity)
 	assert.Equal(t, 1e-06, amount.InexactFloat64())
 }
+
+func TestHasFileStableIDs(t *testing.T) {
+	assert.True(t, HasFileStableIDs(LedgerCLI{}))
+	assert.True(t, HasFileStableIDs(Beancount{}))
+	assert.True(t, HasFileStableIDs(Native{}))
+	assert.False(t, HasFileStableIDs(HLedgerCLI{}))
+}
+
+func TestLedgerCLIStatus(t *testing.T) {
+	assert.Equal(t, "cleared", flagStatus("*"))
+	assert.Equal(t, "pending", flagStatus("!"))
+	assert.Equal(t, "unmarked", flagStatus(""))
+}
+
+func TestBeancountStatus(t *testing.T) {
+	assert.Equal(t, "cleared", flagStatus("*", "!"))
+	assert.Equal(t, "pending", flagStatus("!", "*"))
+	assert.Equal(t, "cleared", flagStatus("", "*"))
+	assert.Equal(t, "pending", flagStatus("", "!"))
+	assert.Equal(t, "unmarked", flagStatus("", "txn"))
+}
+
+func TestHLedgerStatus(t *testing.T) {
+	var transactions []HLedgerTransaction
+	err := json.Unmarshal([]byte(`[{"tstatus": "Unmarked", "tpostings": [{"pstatus": "Cleared"}, {"pstatus": "Unmarked"}]}, {"tstatus": "Cleared", "tpostings": [{"pstatus": "Pending"}, {"pstatus": "Unmarked"}]}]`), &transactions)
+	assert.NoError(t, err)
+
+	status := func(i, j int) string {
+		return hledgerStatus(transactions[i].Postings[j].Status, transactions[i].Status)
+	}
+	assert.Equal(t, "cleared", status(0, 0))
+	assert.Equal(t, "unmarked", status(0, 1))
+	assert.Equal(t, "pending", status(1, 0))
+	assert.Equal(t, "cleared", status(1, 1))
+}
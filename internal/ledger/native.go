@@ -0,0 +1,225 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/google/btree"
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/model/price"
+)
+
+// Native parses the journal without depending on any external binary
+type Native struct{}
+
+func (Native) ValidateFile(journalPath string) ([]LedgerFileError, string, error) {
+	errs := []LedgerFileError{}
+
+	journal, err := parseNativeJournal(journalPath)
+	if err != nil {
+		return errs, "", err
+	}
+
+	if len(journal.errors) > 0 {
+		return journal.errors, "", errors.New(journal.errors[0].Message)
+	}
+
+	return errs, nativeBalanceReport(journal), nil
+}
+
+func (n Native) Parse(journalPath string, prices []price.Price) ([]*posting.Posting, error) {
+	return n.ParseFiles(journalPath, prices, nil)
+}
+
+// ParseFiles parses the whole journal but only builds the postings of
+// the given files, which are relative to the journal directory. All the
+// postings are returned if fileNames is nil.
+func (Native) ParseFiles(journalPath string, prices []price.Price, fileNames []string) ([]*posting.Posting, error) {
+	journal, err := parseNativeJournal(journalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(journal.errors) > 0 {
+		return nil, errors.New(journal.errors[0].Message)
+	}
+
+	pricesTree := buildPricesTree(prices)
+	dir := filepath.Dir(config.GetJournalPath())
+	namespace := uuid.Must(uuid.FromString("45964a1b-b24c-4a73-835a-9335a7aa7de5"))
+
+	var postings []*posting.Posting
+	for _, t := range journal.transactions {
+		fileName, err := filepath.Rel(dir, t.fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		if fileNames != nil && !lo.Contains(fileNames, fileName) {
+			continue
+		}
+
+		transactionID := uuid.NewV5(namespace, fileName+":"+strconv.FormatUint(t.beginLine, 10)).String()
+		transactionNote := strings.Join(t.notes, "\n")
+
+		for _, p := range t.postings {
+			status := p.status
+			if status == "" {
+				status = t.status
+			}
+
+			posting := posting.Posting{
+				Date:                 t.date,
+				Payee:                t.payee,
+				Account:              p.account,
+				Commodity:            p.amount.Commodity,
+				Quantity:             p.amount.Quantity,
+				Amount:               nativeMarketAmount(p, t, pricesTree),
+				TransactionID:        transactionID,
+				Status:               nativeStatus(status),
+				TagRecurring:         nativeTag(p, t, "Recurring"),
+				TagPeriod:            nativeTag(p, t, "Period"),
+				TransactionBeginLine: t.beginLine,
+				TransactionEndLine:   t.endLine,
+				Forecast:             false,
+				FileName:             fileName,
+				Note:                 strings.Join(p.notes, "\n"),
+				TransactionNote:      transactionNote}
+			postings = append(postings, &posting)
+		}
+	}
+
+	return postings, nil
+}
+
+// Unsupported describes the periodic and automated transactions of the
+// journal, which are ignored by the native parser. The budget and the
+// forecast depend on the periodic transactions.
+func (Native) Unsupported(journalPath string) ([]string, error) {
+	journal, err := parseNativeJournal(journalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(config.GetJournalPath())
+	return lo.Map(journal.unsupported, func(u nativeUnsupported, _ int) string {
+		fileName, err := filepath.Rel(dir, u.fileName)
+		if err != nil {
+			fileName = u.fileName
+		}
+		return fmt.Sprintf("%s:%d %s", fileName, u.line, u.header)
+	}), nil
+}
+
+func (Native) Prices(journalPath string) ([]price.Price, error) {
+	var prices []price.Price
+
+	journal, err := parseNativeJournal(journalPath)
+	if err != nil {
+		return prices, err
+	}
+
+	defaultCurrency := config.DefaultCurrency()
+	for _, p := range journal.prices {
+		if p.value.Commodity != defaultCurrency || p.commodity == defaultCurrency {
+			continue
+		}
+
+		prices = append(prices, price.Price{Date: p.date, CommodityName: p.commodity, CommodityID: p.commodity, CommodityType: config.Unknown, Value: p.value.Quantity})
+	}
+
+	return prices, nil
+}
+
+// nativeMarketAmount converts the posting amount to the default currency
+func nativeMarketAmount(p *nativePosting, t *nativeTransaction, pricesTree map[string]*btree.BTree) decimal.Decimal {
+	defaultCurrency := config.DefaultCurrency()
+
+	if p.amount.Commodity == defaultCurrency {
+		return p.amount.Quantity
+	}
+
+	if p.lotPrice != nil && p.lotPrice.Commodity == defaultCurrency {
+		return p.lotPrice.Quantity.Mul(p.amount.Quantity)
+	}
+
+	if p.cost != nil && p.cost.Commodity == defaultCurrency {
+		return p.cost.Quantity
+	}
+
+	if pr := lookupPrice(pricesTree, p.amount.Commodity, t.date); !pr.IsZero() {
+		return p.amount.Quantity.Mul(pr)
+	}
+
+	if p.cost != nil {
+		if pr := lookupPrice(pricesTree, p.cost.Commodity, t.date); !pr.IsZero() {
+			return p.cost.Quantity.Mul(pr)
+		}
+	}
+
+	return p.amount.Quantity
+}
+
+func nativeStatus(status string) string {
+	switch status {
+	case "*":
+		return "cleared"
+	case "!":
+		return "pending"
+	default:
+		return "unmarked"
+	}
+}
+
+func nativeTag(p *nativePosting, t *nativeTransaction, name string) string {
+	if value, ok := p.tags[name]; ok {
+		return value
+	}
+	return t.tags[name]
+}
+
+func nativeBalanceReport(journal *nativeJournal) string {
+	type key struct {
+		account   string
+		commodity string
+	}
+
+	balances := make(map[key]decimal.Decimal)
+	for _, t := range journal.transactions {
+		for _, p := range t.postings {
+			k := key{account: p.account, commodity: p.amount.Commodity}
+			balances[k] = balances[k].Add(p.amount.Quantity)
+		}
+	}
+
+	keys := make([]key, 0, len(balances))
+	for k := range balances {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].account == keys[j].account {
+			return keys[i].commodity < keys[j].commodity
+		}
+		return keys[i].account < keys[j].account
+	})
+
+	var report strings.Builder
+	for _, k := range keys {
+		if balances[k].IsZero() {
+			continue
+		}
+		amount := nativeAmount{Commodity: k.commodity, Quantity: balances[k]}
+		fmt.Fprintf(&report, "%20s  %s\n", amount.String(), k.account)
+	}
+
+	return report.String()
+}
@@ -0,0 +1,704 @@
+package ledger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/utils"
+)
+
+// The native parser understands the commonly used subset of the
+// ledger-cli journal format: transactions, postings with cost and lot
+// prices, price directives, includes, tags and metadata, account
+// aliases and elided amounts. Periodic and automated transactions are
+// ignored.
+
+type nativeAmount struct {
+	Commodity string
+	Quantity  decimal.Decimal
+}
+
+func (a nativeAmount) String() string {
+	if a.Commodity == "" {
+		return a.Quantity.String()
+	}
+	return a.Quantity.String() + " " + a.Commodity
+}
+
+type nativePosting struct {
+	line    uint64
+	account string
+	// virtual is '(' for unbalanced and '[' for balanced virtual postings
+	virtual byte
+	status  string
+	amount  *nativeAmount
+	// cost is the total cost, it has the same sign as the amount
+	cost *nativeAmount
+	// lotPrice is the per unit price
+	lotPrice *nativeAmount
+	notes    []string
+	tags     map[string]string
+}
+
+type nativeTransaction struct {
+	fileName  string
+	beginLine uint64
+	endLine   uint64
+	date      time.Time
+	status    string
+	payee     string
+	notes     []string
+	tags      map[string]string
+	postings  []*nativePosting
+	invalid   bool
+}
+
+type nativePrice struct {
+	date      time.Time
+	commodity string
+	value     nativeAmount
+}
+
+type nativeUnsupported struct {
+	fileName string
+	line     uint64
+	header   string
+}
+
+type nativeJournal struct {
+	transactions []*nativeTransaction
+	prices       []nativePrice
+	errors       []LedgerFileError
+	// unsupported holds the periodic and automated transactions, which
+	// are ignored
+	unsupported []nativeUnsupported
+
+	accounts    map[string]bool
+	commodities map[string]bool
+	aliases     map[string]string
+	applyStack  []string
+	precision   map[string]int32
+	including   map[string]bool
+}
+
+var (
+	nativeHeaderRegex   = regexp.MustCompile(`^(\d{4}[/.-]\d{1,2}[/.-]\d{1,2})(?:=\S+)?(?:\s+([*!]))?(?:\s+\(([^)]*)\))?(?:\s+(.*))?$`)
+	nativePriceRegex    = regexp.MustCompile(`^P\s+(\d{4}[/.-]\d{1,2}[/.-]\d{1,2})(?:\s+\d{1,2}:\d{2}(?::\d{2})?)?\s+("[^"]+"|\S+)\s+(.+)$`)
+	nativeAmountRegex   = regexp.MustCompile(`^(-)?\s*(?:("[^"]+"|[^\s\d.,+\-"]+)\s*)?([+-]?[\d.,]*\d[\d.,]*)\s*("[^"]+"|[^\s\d.,+\-"]+)?$`)
+	nativeLotRegex      = regexp.MustCompile(`\{\{([^}]*)\}\}|\{([^}]*)\}`)
+	nativeLotDateRegex  = regexp.MustCompile(`\[[^\]]*\]`)
+	nativeLotNoteRegex  = regexp.MustCompile(`\([^)]*\)`)
+	nativeTagsRegex     = regexp.MustCompile(`^:(?:[^\s:]+:)+$`)
+	nativeMetadataRegex = regexp.MustCompile(`^([^\s:]+)::?\s*(.*)$`)
+
+	nativeUnsupportedWarning sync.Once
+)
+
+func parseNativeJournal(journalPath string) (*nativeJournal, error) {
+	j := &nativeJournal{
+		accounts:    make(map[string]bool),
+		commodities: make(map[string]bool),
+		aliases:     make(map[string]string),
+		precision:   make(map[string]int32),
+		including:   make(map[string]bool),
+	}
+
+	content, err := os.ReadFile(journalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	j.parseFile(journalPath, content)
+	return j, nil
+}
+
+func (j *nativeJournal) addError(fileName string, lineFrom uint64, lineTo uint64, format string, args ...any) {
+	message := fmt.Sprintf("While parsing file \"%s\", line %d:\nError: %s", fileName, lineFrom, fmt.Sprintf(format, args...))
+	j.errors = append(j.errors, LedgerFileError{LineFrom: lineFrom, LineTo: lineTo, Message: message})
+}
+
+func (j *nativeJournal) parseFile(fileName string, content []byte) {
+	absPath, _ := filepath.Abs(fileName)
+	j.including[absPath] = true
+	defer delete(j.including, absPath)
+
+	var transaction *nativeTransaction
+	skipBlock := false
+	blockComment := ""
+
+	finish := func() {
+		if transaction != nil {
+			j.finishTransaction(transaction)
+			transaction = nil
+		}
+	}
+
+	lines := strings.Split(utils.Dos2Unix(string(content)), "\n")
+	for i, line := range lines {
+		lineNumber := uint64(i + 1)
+		line = strings.TrimRight(line, " \t")
+
+		if blockComment != "" {
+			if strings.TrimSpace(line) == "end "+blockComment {
+				blockComment = ""
+			}
+			continue
+		}
+
+		if line == "" {
+			finish()
+			skipBlock = false
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			content := strings.TrimSpace(line)
+			if transaction != nil {
+				transaction.endLine = lineNumber
+				j.parseTransactionLine(transaction, fileName, lineNumber, content)
+			} else if !skipBlock && !isNativeComment(content) {
+				j.addError(fileName, lineNumber, lineNumber, "Unexpected whitespace at beginning of line")
+			}
+			continue
+		}
+
+		finish()
+		skipBlock = false
+
+		switch {
+		case line[0] >= '0' && line[0] <= '9':
+			transaction = j.parseTransactionHeader(fileName, lineNumber, line)
+		case isNativeComment(line):
+			continue
+		case line[0] == 'P' && len(line) > 1 && (line[1] == ' ' || line[1] == '\t'):
+			j.parsePrice(fileName, lineNumber, line)
+		case line[0] == '~' || line[0] == '=':
+			j.unsupported = append(j.unsupported, nativeUnsupported{fileName: fileName, line: lineNumber, header: line})
+			nativeUnsupportedWarning.Do(func() {
+				log.Warn("Periodic and automated transactions are not supported by the native ledger parser, they will be ignored")
+			})
+			skipBlock = true
+		default:
+			var end bool
+			skipBlock, end = j.parseDirective(fileName, lineNumber, line)
+			if end {
+				blockComment = strings.Fields(line)[0]
+			}
+		}
+	}
+
+	finish()
+}
+
+func isNativeComment(line string) bool {
+	return strings.ContainsRune(";#%|*", rune(line[0]))
+}
+
+// parseDirective handles the top level directives, it returns whether
+// the indented lines following the directive should be skipped and
+// whether the directive starts a block comment.
+func (j *nativeJournal) parseDirective(fileName string, lineNumber uint64, line string) (bool, bool) {
+	name := strings.Fields(line)[0]
+	argument, _ := splitNativeComment(strings.TrimSpace(line[len(name):]))
+
+	switch name {
+	case "include", "!include", "@include":
+		j.include(fileName, lineNumber, argument)
+	case "account":
+		j.accounts[j.expandAccount(argument)] = true
+		return true, false
+	case "commodity":
+		j.commodities[utils.UnQuote(argument)] = true
+		return true, false
+	case "alias":
+		from, to, found := strings.Cut(argument, "=")
+		if !found {
+			j.addError(fileName, lineNumber, lineNumber, "Invalid alias directive")
+			break
+		}
+		j.aliases[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	case "apply":
+		fields := strings.Fields(argument)
+		if len(fields) >= 2 && fields[0] == "account" {
+			j.applyStack = append(j.applyStack, strings.TrimSpace(strings.TrimPrefix(argument, "account")))
+		} else {
+			j.applyStack = append(j.applyStack, "")
+		}
+	case "end":
+		if len(j.applyStack) > 0 {
+			j.applyStack = j.applyStack[:len(j.applyStack)-1]
+		}
+	case "comment", "test":
+		return false, true
+	case "payee", "tag", "define", "bucket", "A", "D", "C", "N", "assert", "check", "expr", "value", "python", "eval", "import", "option", "year", "Y", "def":
+		return true, false
+	default:
+		j.addError(fileName, lineNumber, lineNumber, "Unexpected directive '%s'", name)
+	}
+
+	return false, false
+}
+
+func (j *nativeJournal) include(fileName string, lineNumber uint64, pattern string) {
+	pattern = utils.UnQuote(pattern)
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(fileName), pattern)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil || len(matches) == 0 {
+		j.addError(fileName, lineNumber, lineNumber, "File to include was not found: \"%s\"", pattern)
+		return
+	}
+
+	sort.Strings(matches)
+	for _, match := range matches {
+		absPath, _ := filepath.Abs(match)
+		if j.including[absPath] {
+			j.addError(fileName, lineNumber, lineNumber, "File \"%s\" includes itself", match)
+			continue
+		}
+
+		content, err := os.ReadFile(match)
+		if err != nil {
+			j.addError(fileName, lineNumber, lineNumber, "Failed to read \"%s\": %v", match, err)
+			continue
+		}
+
+		j.parseFile(match, content)
+	}
+}
+
+func parseNativeDate(date string) (time.Time, error) {
+	date = strings.NewReplacer("-", "/", ".", "/").Replace(date)
+	return time.ParseInLocation("2006/1/2", date, config.TimeZone())
+}
+
+func (j *nativeJournal) parsePrice(fileName string, lineNumber uint64, line string) {
+	line, _ = splitNativeComment(line)
+	match := nativePriceRegex.FindStringSubmatch(line)
+	if match == nil {
+		j.addError(fileName, lineNumber, lineNumber, "Invalid price directive")
+		return
+	}
+
+	date, err := parseNativeDate(match[1])
+	if err != nil {
+		j.addError(fileName, lineNumber, lineNumber, "Invalid date %s", match[1])
+		return
+	}
+
+	value, err := j.parseAmount(match[3])
+	if err != nil {
+		j.addError(fileName, lineNumber, lineNumber, "%v", err)
+		return
+	}
+
+	j.prices = append(j.prices, nativePrice{date: date, commodity: utils.UnQuote(match[2]), value: value})
+}
+
+func (j *nativeJournal) parseTransactionHeader(fileName string, lineNumber uint64, line string) *nativeTransaction {
+	transaction := &nativeTransaction{fileName: fileName, beginLine: lineNumber, endLine: lineNumber, tags: make(map[string]string)}
+
+	match := nativeHeaderRegex.FindStringSubmatch(line)
+	if match == nil {
+		j.addError(fileName, lineNumber, lineNumber, "Invalid transaction header")
+		transaction.invalid = true
+		return transaction
+	}
+
+	date, err := parseNativeDate(match[1])
+	if err != nil {
+		j.addError(fileName, lineNumber, lineNumber, "Invalid date %s", match[1])
+		transaction.invalid = true
+		return transaction
+	}
+
+	transaction.date = date
+	transaction.status = match[2]
+	payee, note := splitNativeComment(match[4])
+	transaction.payee = payee
+	if note != "" {
+		addNativeComment(&transaction.notes, transaction.tags, note)
+	}
+
+	return transaction
+}
+
+func (j *nativeJournal) parseTransactionLine(transaction *nativeTransaction, fileName string, lineNumber uint64, content string) {
+	if transaction.invalid {
+		return
+	}
+
+	if content[0] == ';' || content[0] == '#' {
+		comment := strings.TrimSpace(content[1:])
+		if len(transaction.postings) > 0 {
+			p := transaction.postings[len(transaction.postings)-1]
+			addNativeComment(&p.notes, p.tags, comment)
+		} else {
+			addNativeComment(&transaction.notes, transaction.tags, comment)
+		}
+		return
+	}
+
+	p, err := j.parsePosting(lineNumber, content)
+	if err != nil {
+		j.addError(fileName, lineNumber, lineNumber, "%v", err)
+		transaction.invalid = true
+		return
+	}
+
+	transaction.postings = append(transaction.postings, p)
+}
+
+func (j *nativeJournal) parsePosting(lineNumber uint64, content string) (*nativePosting, error) {
+	p := &nativePosting{line: lineNumber, tags: make(map[string]string)}
+
+	if content[0] == '*' || content[0] == '!' {
+		p.status = content[:1]
+		content = strings.TrimSpace(content[1:])
+	}
+
+	body, note := splitNativeComment(content)
+	if note != "" {
+		addNativeComment(&p.notes, p.tags, note)
+	}
+
+	account, rest := splitNativeAccount(body)
+	if len(account) > 2 && (account[0] == '(' && account[len(account)-1] == ')' || account[0] == '[' && account[len(account)-1] == ']') {
+		p.virtual = account[0]
+		account = account[1 : len(account)-1]
+	}
+	p.account = j.expandAccount(account)
+
+	if config.GetConfig().Strict == config.Yes && !j.accounts[p.account] {
+		return nil, fmt.Errorf("Unknown account '%s'", p.account)
+	}
+
+	if rest == "" {
+		return p, nil
+	}
+
+	if index := indexOutsideQuotes(rest, '='); index >= 0 {
+		// balance assertions are not verified
+		rest = strings.TrimSpace(rest[:index])
+		if rest == "" {
+			return nil, fmt.Errorf("Balance assignments are not supported by the native parser")
+		}
+	}
+
+	costPart := ""
+	totalCost := false
+	if index := indexOutsideQuotes(rest, '@'); index >= 0 {
+		costPart = rest[index+1:]
+		if strings.HasPrefix(costPart, "@") {
+			totalCost = true
+			costPart = costPart[1:]
+		}
+		rest = rest[:index]
+	}
+
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") {
+		return nil, fmt.Errorf("Value expressions are not supported by the native parser")
+	}
+
+	var lotPart string
+	totalLot := false
+	if match := nativeLotRegex.FindStringSubmatch(rest); match != nil {
+		if match[1] != "" {
+			lotPart = match[1]
+			totalLot = true
+		} else {
+			lotPart = match[2]
+		}
+		rest = nativeLotRegex.ReplaceAllString(rest, "")
+	}
+	rest = nativeLotDateRegex.ReplaceAllString(rest, "")
+	rest = nativeLotNoteRegex.ReplaceAllString(rest, "")
+
+	amount, err := j.parseAmount(rest)
+	if err != nil {
+		return nil, err
+	}
+	p.amount = &amount
+
+	if lotPart != "" {
+		lot, err := j.parseAmount(strings.TrimPrefix(strings.TrimSpace(lotPart), "="))
+		if err != nil {
+			return nil, err
+		}
+		if totalLot && !amount.Quantity.IsZero() {
+			lot.Quantity = lot.Quantity.Div(amount.Quantity.Abs())
+		}
+		p.lotPrice = &lot
+	}
+
+	if costPart != "" {
+		cost, err := j.parseAmount(costPart)
+		if err != nil {
+			return nil, err
+		}
+		if totalCost {
+			cost.Quantity = cost.Quantity.Abs()
+			if amount.Quantity.IsNegative() {
+				cost.Quantity = cost.Quantity.Neg()
+			}
+		} else {
+			cost.Quantity = cost.Quantity.Mul(amount.Quantity)
+		}
+		p.cost = &cost
+	}
+
+	return p, nil
+}
+
+func (j *nativeJournal) parseAmount(s string) (nativeAmount, error) {
+	s = strings.TrimSpace(s)
+	match := nativeAmountRegex.FindStringSubmatch(s)
+	if match == nil || (match[2] != "" && match[4] != "") {
+		return nativeAmount{}, fmt.Errorf("Could not parse amount: <%s>", s)
+	}
+
+	number := strings.ReplaceAll(match[3], ",", "")
+	quantity, err := decimal.NewFromString(number)
+	if err != nil {
+		return nativeAmount{}, fmt.Errorf("Could not parse amount: <%s>", s)
+	}
+
+	if match[1] == "-" {
+		quantity = quantity.Neg()
+	}
+
+	commodity := utils.UnQuote(match[2] + match[4])
+	if config.GetConfig().Strict == config.Yes && commodity != "" && !j.commodities[commodity] {
+		return nativeAmount{}, fmt.Errorf("Unknown commodity '%s'", commodity)
+	}
+
+	if precision := -quantity.Exponent(); precision > j.precision[commodity] {
+		j.precision[commodity] = precision
+	}
+
+	return nativeAmount{Commodity: commodity, Quantity: quantity}, nil
+}
+
+func (j *nativeJournal) expandAccount(account string) string {
+	account = strings.TrimSpace(account)
+
+	for i := len(j.applyStack) - 1; i >= 0; i-- {
+		if j.applyStack[i] != "" {
+			account = j.applyStack[i] + ":" + account
+		}
+	}
+
+	first, rest, found := strings.Cut(account, ":")
+	if alias, ok := j.aliases[first]; ok {
+		if found {
+			return alias + ":" + rest
+		}
+		return alias
+	}
+
+	return account
+}
+
+// weight returns the amount used to balance the posting
+func (p *nativePosting) weight() nativeAmount {
+	if p.cost != nil {
+		return *p.cost
+	}
+
+	if p.lotPrice != nil {
+		return nativeAmount{Commodity: p.lotPrice.Commodity, Quantity: p.lotPrice.Quantity.Mul(p.amount.Quantity)}
+	}
+
+	return *p.amount
+}
+
+func (j *nativeJournal) isZero(commodity string, quantity decimal.Decimal) bool {
+	return quantity.Round(j.precision[commodity]).IsZero()
+}
+
+func (j *nativeJournal) finishTransaction(t *nativeTransaction) {
+	if t.invalid {
+		return
+	}
+
+	if len(t.postings) == 0 {
+		j.addError(t.fileName, t.beginLine, t.endLine, "Transaction has no postings")
+		return
+	}
+
+	// unbalanced virtual postings are not part of any group
+	for _, group := range []byte{0, '['} {
+		var postings []*nativePosting
+		for _, p := range t.postings {
+			if p.virtual == group {
+				postings = append(postings, p)
+			}
+		}
+
+		if len(postings) > 0 && !j.balance(t, postings) {
+			return
+		}
+	}
+
+	for _, p := range t.postings {
+		if p.amount == nil {
+			p.amount = &nativeAmount{Commodity: config.DefaultCurrency(), Quantity: decimal.Zero}
+		}
+
+		if p.cost != nil && !p.amount.Quantity.IsZero() {
+			j.prices = append(j.prices, nativePrice{date: t.date, commodity: p.amount.Commodity, value: nativeAmount{Commodity: p.cost.Commodity, Quantity: p.cost.Quantity.Div(p.amount.Quantity).Abs()}})
+		}
+	}
+
+	j.transactions = append(j.transactions, t)
+}
+
+// balance fills in the elided amount and verifies that the postings
+// balance, the cost of a commodity is inferred if the transaction has
+// exactly two commodities.
+func (j *nativeJournal) balance(t *nativeTransaction, postings []*nativePosting) bool {
+	var commodities []string
+	sums := make(map[string]decimal.Decimal)
+	var elided *nativePosting
+
+	for _, p := range postings {
+		if p.amount == nil {
+			if elided != nil {
+				j.addError(t.fileName, p.line, p.line, "Only one posting with null amount allowed per transaction")
+				return false
+			}
+			elided = p
+			continue
+		}
+
+		weight := p.weight()
+		if _, ok := sums[weight.Commodity]; !ok {
+			commodities = append(commodities, weight.Commodity)
+		}
+		sums[weight.Commodity] = sums[weight.Commodity].Add(weight.Quantity)
+	}
+
+	var unbalanced []nativeAmount
+	for _, commodity := range commodities {
+		if !j.isZero(commodity, sums[commodity]) {
+			unbalanced = append(unbalanced, nativeAmount{Commodity: commodity, Quantity: sums[commodity]})
+		}
+	}
+
+	if elided != nil {
+		if len(unbalanced) == 0 {
+			commodity := config.DefaultCurrency()
+			if len(commodities) > 0 {
+				commodity = commodities[0]
+			}
+			elided.amount = &nativeAmount{Commodity: commodity, Quantity: decimal.Zero}
+			return true
+		}
+
+		elided.amount = &nativeAmount{Commodity: unbalanced[0].Commodity, Quantity: unbalanced[0].Quantity.Neg()}
+
+		// an elided amount balancing multiple commodities becomes one
+		// posting per commodity
+		index := lo.IndexOf(t.postings, elided)
+		for _, u := range unbalanced[1:] {
+			clone := *elided
+			clone.amount = &nativeAmount{Commodity: u.Commodity, Quantity: u.Quantity.Neg()}
+			index++
+			t.postings = append(t.postings[:index], append([]*nativePosting{&clone}, t.postings[index:]...)...)
+		}
+		return true
+	}
+
+	switch len(unbalanced) {
+	case 0:
+		return true
+	case 2:
+		// the cost is inferred for the commodity which is not the
+		// default currency, irrespective of the order of the postings
+		first, second := unbalanced[0], unbalanced[1]
+		if first.Commodity == config.DefaultCurrency() {
+			first, second = second, first
+		}
+		if first.Quantity.Sign() != second.Quantity.Sign() {
+			for _, p := range postings {
+				if p.cost == nil && p.lotPrice == nil && p.amount.Commodity == first.Commodity {
+					p.cost = &nativeAmount{Commodity: second.Commodity, Quantity: second.Quantity.Neg().Mul(p.amount.Quantity).Div(first.Quantity)}
+				}
+			}
+			return true
+		}
+	}
+
+	amounts := make([]string, len(unbalanced))
+	for i, u := range unbalanced {
+		amounts[i] = u.String()
+	}
+	j.addError(t.fileName, t.beginLine, t.endLine, "Transaction does not balance, unbalanced remainder is %s", strings.Join(amounts, ", "))
+	return false
+}
+
+// splitNativeComment splits the line at the first ; not inside quotes
+func splitNativeComment(line string) (string, string) {
+	index := indexOutsideQuotes(line, ';')
+	if index < 0 {
+		return strings.TrimSpace(line), ""
+	}
+	return strings.TrimSpace(line[:index]), strings.TrimSpace(line[index+1:])
+}
+
+// splitNativeAccount splits the posting at the first tab or two spaces
+func splitNativeAccount(line string) (string, string) {
+	index := -1
+	if i := strings.Index(line, "  "); i >= 0 {
+		index = i
+	}
+	if i := strings.Index(line, "\t"); i >= 0 && (index < 0 || i < index) {
+		index = i
+	}
+
+	if index < 0 {
+		return strings.TrimSpace(line), ""
+	}
+	return strings.TrimSpace(line[:index]), strings.TrimSpace(line[index:])
+}
+
+func indexOutsideQuotes(s string, c byte) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			quoted = !quoted
+		} else if s[i] == c && !quoted {
+			return i
+		}
+	}
+	return -1
+}
+
+func addNativeComment(notes *[]string, tags map[string]string, comment string) {
+	*notes = append(*notes, comment)
+
+	if nativeTagsRegex.MatchString(comment) {
+		for _, tag := range strings.Split(strings.Trim(comment, ":"), ":") {
+			tags[tag] = ""
+		}
+		return
+	}
+
+	if match := nativeMetadataRegex.FindStringSubmatch(comment); match != nil {
+		tags[match[1]] = strings.TrimSpace(match[2])
+	}
+}
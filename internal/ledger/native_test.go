@@ -0,0 +1,204 @@
+package ledger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+)
+
+func writeNativeJournal(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	journalPath := filepath.Join(dir, "main.ledger")
+	err := config.LoadConfig([]byte("journal_path: "+journalPath+"\ndb_path: paisa.db\n"), filepath.Join(dir, "paisa.yaml"))
+	assert.NoError(t, err)
+	return journalPath
+}
+
+func TestNativeParse(t *testing.T) {
+	journalPath := writeNativeJournal(t, map[string]string{
+		"main.ledger": `; opening
+P 2023/01/01 NIFTY 100 INR
+
+2023/01/02 * Salary ; :income:
+    Assets:Checking    10,000 INR
+    Income:Salary
+
+2023/01/03 Buy NIFTY
+    ; Recurring: monthly
+    Assets:Equity:NIFTY    10 NIFTY @ 110 INR
+    Assets:Checking
+
+include expenses.ledger
+`,
+		"expenses.ledger": `2023/01/04 ! Rent
+    Expenses:Rent    5000 INR  ; Period: 2023/01
+    * Assets:Checking    -5000 INR
+`,
+	})
+
+	postings, err := Native{}.Parse(journalPath, nil)
+	assert.NoError(t, err)
+	assert.Len(t, postings, 6)
+
+	assert.Equal(t, "Income:Salary", postings[1].Account)
+	assert.Equal(t, -10000.0, postings[1].Amount.InexactFloat64())
+	assert.Equal(t, "cleared", postings[1].Status)
+	assert.Equal(t, ":income:", postings[0].TransactionNote)
+	assert.Equal(t, uint64(4), postings[0].TransactionBeginLine)
+	assert.Equal(t, uint64(6), postings[0].TransactionEndLine)
+
+	assert.Equal(t, "NIFTY", postings[2].Commodity)
+	assert.Equal(t, 10.0, postings[2].Quantity.InexactFloat64())
+	assert.Equal(t, 1100.0, postings[2].Amount.InexactFloat64())
+	assert.Equal(t, "monthly", postings[2].TagRecurring)
+	assert.Equal(t, -1100.0, postings[3].Amount.InexactFloat64())
+
+	assert.Equal(t, "expenses.ledger", postings[4].FileName)
+	assert.Equal(t, "pending", postings[4].Status)
+	assert.Equal(t, "2023/01", postings[4].TagPeriod)
+	assert.Equal(t, "cleared", postings[5].Status)
+
+	prices, err := Native{}.Prices(journalPath)
+	assert.NoError(t, err)
+	assert.Len(t, prices, 2)
+	assertPriceEqual(t, prices[0], "2023/01/01", "NIFTY", 100)
+	assertPriceEqual(t, prices[1], "2023/01/03", "NIFTY", 110)
+}
+
+func TestNativeInferredCost(t *testing.T) {
+	journalPath := writeNativeJournal(t, map[string]string{
+		"main.ledger": `2023/01/03 Buy
+    Assets:Equity:NIFTY    10 NIFTY {100 INR}
+    Assets:Checking    -1000 INR
+
+2023/01/04 Exchange
+    Assets:USD    10 USD
+    Assets:Checking    -800 INR
+`,
+	})
+
+	postings, err := Native{}.Parse(journalPath, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000.0, postings[0].Amount.InexactFloat64())
+	assert.Equal(t, 800.0, postings[2].Amount.InexactFloat64())
+
+	prices, _ := Native{}.Prices(journalPath)
+	assertPriceEqual(t, prices[0], "2023/01/04", "USD", 80)
+}
+
+func TestNativeInferredCostReversed(t *testing.T) {
+	journalPath := writeNativeJournal(t, map[string]string{
+		"main.ledger": `2023/01/04 Exchange
+    Assets:Checking    -800 INR
+    Assets:USD    10 USD
+`,
+	})
+
+	postings, err := Native{}.Parse(journalPath, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, -800.0, postings[0].Amount.InexactFloat64())
+	assert.Equal(t, 800.0, postings[1].Amount.InexactFloat64())
+	assert.Equal(t, 10.0, postings[1].Quantity.InexactFloat64())
+
+	prices, _ := Native{}.Prices(journalPath)
+	assertPriceEqual(t, prices[0], "2023/01/04", "USD", 80)
+}
+
+func TestNativeValidateFile(t *testing.T) {
+	journalPath := writeNativeJournal(t, map[string]string{
+		"main.ledger": `2023/01/02 Salary
+    Assets:Checking    10000 INR
+    Income:Salary
+
+2023/01/03 Unbalanced
+    Assets:Checking    100 INR
+    Income:Salary    -90 INR
+
+2023/13/03 Invalid date
+    Assets:Checking    100 INR
+    Income:Salary
+`,
+	})
+
+	errors, _, err := Native{}.ValidateFile(journalPath)
+	assert.Error(t, err)
+	assert.Len(t, errors, 2)
+	assert.Equal(t, uint64(5), errors[0].LineFrom)
+	assert.Equal(t, uint64(7), errors[0].LineTo)
+	assert.Contains(t, errors[0].Message, "Transaction does not balance")
+	assert.Equal(t, uint64(9), errors[1].LineFrom)
+}
+
+func TestFingerprint(t *testing.T) {
+	journalPath := writeNativeJournal(t, map[string]string{
+		"main.ledger": "alias Checking = Assets:Checking\ninclude expenses.ledger\n",
+		"expenses.ledger": `2023/01/04 Rent
+    Expenses:Rent    5000 INR
+    Checking
+`,
+	})
+
+	hashes, err := Fingerprint(journalPath)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, 3)
+
+	expenses := filepath.Join(filepath.Dir(journalPath), "expenses.ledger")
+	assert.NoError(t, os.WriteFile(expenses, []byte("2023/01/04 Rent\n    Expenses:Rent    6000 INR\n    Checking\n"), 0644))
+	updated, _ := Fingerprint(journalPath)
+	assert.Equal(t, hashes[ContextFile], updated[ContextFile])
+	assert.Equal(t, hashes["main.ledger"], updated["main.ledger"])
+	assert.NotEqual(t, hashes["expenses.ledger"], updated["expenses.ledger"])
+
+	assert.NoError(t, os.WriteFile(journalPath, []byte("alias Checking = Assets:Bank\ninclude expenses.ledger\n"), 0644))
+	updated, _ = Fingerprint(journalPath)
+	assert.NotEqual(t, hashes[ContextFile], updated[ContextFile])
+}
+
+func TestFingerprintBeancountDirectives(t *testing.T) {
+	journalPath := writeNativeJournal(t, map[string]string{
+		"main.ledger": "include \"accounts.beancount\"\n",
+		"accounts.beancount": `2023-01-01 open Assets:Checking INR
+
+2023-01-04 * "Rent"
+  Expenses:Rent  5000 INR
+  Assets:Checking
+`,
+	})
+
+	hashes, err := Fingerprint(journalPath)
+	assert.NoError(t, err)
+
+	accounts := filepath.Join(filepath.Dir(journalPath), "accounts.beancount")
+	assert.NoError(t, os.WriteFile(accounts, []byte("2023-01-01 open Assets:Checking INR\n\n2023-01-04 * \"Rent\"\n  Expenses:Rent  6000 INR\n  Assets:Checking\n"), 0644))
+	updated, _ := Fingerprint(journalPath)
+	assert.Equal(t, hashes[ContextFile], updated[ContextFile])
+
+	assert.NoError(t, os.WriteFile(accounts, []byte("2023-01-01 open Assets:Bank INR\n\n2023-01-04 * \"Rent\"\n  Expenses:Rent  6000 INR\n  Assets:Checking\n"), 0644))
+	updated, _ = Fingerprint(journalPath)
+	assert.NotEqual(t, hashes[ContextFile], updated[ContextFile])
+}
+
+func TestNativeUnsupported(t *testing.T) {
+	journalPath := writeNativeJournal(t, map[string]string{
+		"main.ledger": `~ Monthly
+    Expenses:Rent    5000 INR
+    Assets:Checking
+
+2023/01/04 Rent
+    Expenses:Rent    5000 INR
+    Assets:Checking
+`,
+	})
+
+	unsupported, err := Native{}.Unsupported(journalPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.ledger:1 ~ Monthly"}, unsupported)
+}
@@ -0,0 +1,186 @@
+package loan
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/shopspring/decimal"
+)
+
+// maxInstallments stops the schedule of the loans with a fixed EMI
+// which would take more than a century to repay
+const maxInstallments = 1200
+
+type Installment struct {
+	Date      time.Time       `json:"date"`
+	Rate      decimal.Decimal `json:"rate"`
+	EMI       decimal.Decimal `json:"emi"`
+	Interest  decimal.Decimal `json:"interest"`
+	Principal decimal.Decimal `json:"principal"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+type rateChange struct {
+	date time.Time
+	rate decimal.Decimal
+}
+
+func InterestAccount(conf config.Loan) string {
+	if conf.InterestAccount != "" {
+		return conf.InterestAccount
+	}
+	return "Expenses:Interest:" + strings.TrimPrefix(conf.Account, "Liabilities:")
+}
+
+func PaymentAccount(conf config.Loan) string {
+	if conf.PaymentAccount != "" {
+		return conf.PaymentAccount
+	}
+	return "Assets:Checking"
+}
+
+// Schedule returns the monthly installments of the loan. The interest
+// of an installment is charged on the balance after the previous
+// installment at the rate effective on the installment date. Unless
+// the EMI is fixed, it is recomputed over the remaining tenure on
+// every rate change.
+func Schedule(conf config.Loan) ([]Installment, error) {
+	start, err := time.ParseInLocation("2006-01-02", conf.StartDate, config.TimeZone())
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid start date %s of the loan %s", conf.StartDate, conf.Name))
+	}
+
+	changes := []rateChange{}
+	for _, change := range conf.RateChanges {
+		date, err := time.ParseInLocation("2006-01-02", change.Date, config.TimeZone())
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid rate change date %s of the loan %s", change.Date, conf.Name))
+		}
+		changes = append(changes, rateChange{date: date, rate: decimal.NewFromFloat(change.Rate)})
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].date.Before(changes[j].date) })
+
+	fixed := conf.EMI > 0
+	balance := decimal.NewFromFloat(conf.Principal)
+	rate := decimal.NewFromFloat(conf.Rate)
+	emi := decimal.NewFromFloat(conf.EMI)
+	if !fixed {
+		emi = computeEMI(balance, rate, conf.Tenure)
+	}
+
+	installments := []Installment{}
+	for i := 0; balance.IsPositive(); i++ {
+		if i >= maxInstallments {
+			return nil, errors.New(fmt.Sprintf("The loan %s is not repaid within %d installments", conf.Name, maxInstallments))
+		}
+
+		date := addMonths(start, i)
+		changed := false
+		for len(changes) > 0 && !changes[0].date.After(date) {
+			rate = changes[0].rate
+			changes = changes[1:]
+			changed = true
+		}
+
+		remaining := conf.Tenure - i
+		if changed && !fixed && remaining > 0 {
+			emi = computeEMI(balance, rate, remaining)
+		}
+
+		interest := balance.Mul(monthlyRate(rate)).Round(2)
+		payment := emi
+		if (!fixed && remaining <= 1) || balance.Add(interest).LessThanOrEqual(emi) {
+			payment = balance.Add(interest)
+		}
+
+		if payment.LessThanOrEqual(interest) {
+			return nil, errors.New(fmt.Sprintf("The EMI %s of the loan %s doesn't cover the interest %s on %s", payment.String(), conf.Name, interest.String(), date.Format("2006-01-02")))
+		}
+
+		principal := payment.Sub(interest)
+		balance = balance.Sub(principal)
+		installments = append(installments, Installment{
+			Date:      date,
+			Rate:      rate,
+			EMI:       payment,
+			Interest:  interest,
+			Principal: principal,
+			Balance:   balance,
+		})
+	}
+
+	return installments, nil
+}
+
+// Postings returns the forecast transactions of the installments
+// between from and until
+func Postings(conf config.Loan, installments []Installment, from time.Time, until time.Time) []*posting.Posting {
+	postings := []*posting.Posting{}
+	for _, installment := range installments {
+		if installment.Date.Before(from) || !installment.Date.Before(until) {
+			continue
+		}
+
+		transactionID := fmt.Sprintf("loan:%s:%s", conf.Account, installment.Date.Format("2006-01-02"))
+		split := []struct {
+			account string
+			amount  decimal.Decimal
+		}{
+			{conf.Account, installment.Principal},
+			{InterestAccount(conf), installment.Interest},
+			{PaymentAccount(conf), installment.EMI.Neg()},
+		}
+
+		for _, s := range split {
+			if s.amount.IsZero() {
+				continue
+			}
+
+			postings = append(postings, &posting.Posting{
+				TransactionID: transactionID,
+				Date:          installment.Date,
+				Payee:         conf.Name,
+				Account:       s.account,
+				Commodity:     config.DefaultCurrency(),
+				Quantity:      s.amount,
+				Amount:        s.amount,
+				Status:        "unmarked",
+				Forecast:      true,
+			})
+		}
+	}
+	return postings
+}
+
+func monthlyRate(rate decimal.Decimal) decimal.Decimal {
+	return rate.Div(decimal.NewFromInt(1200))
+}
+
+// computeEMI returns the equated monthly installment which repays the
+// balance in the given number of months
+func computeEMI(balance decimal.Decimal, rate decimal.Decimal, months int) decimal.Decimal {
+	if months <= 0 {
+		return balance
+	}
+
+	r := monthlyRate(rate)
+	if r.IsZero() {
+		return balance.Div(decimal.NewFromInt(int64(months))).Round(2)
+	}
+
+	growth := decimal.NewFromInt(1).Add(r).Pow(decimal.NewFromInt(int64(months)))
+	return balance.Mul(r).Mul(growth).Div(growth.Sub(decimal.NewFromInt(1))).Round(2)
+}
+
+// addMonths keeps the day of the month of the date, limited to the
+// last day of the month
+func addMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(date.Day(), last)-1)
+}
@@ -0,0 +1,87 @@
+package loan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func total(installments []Installment, field func(Installment) decimal.Decimal) decimal.Decimal {
+	sum := decimal.Zero
+	for _, installment := range installments {
+		sum = sum.Add(field(installment))
+	}
+	return sum
+}
+
+func TestSchedule(t *testing.T) {
+	conf := config.Loan{Name: "Car", Account: "Liabilities:Car", Principal: 100000, Rate: 12, Tenure: 12, StartDate: "2024-01-31"}
+	installments, err := Schedule(conf)
+	assert.NoError(t, err)
+	assert.Len(t, installments, 12)
+
+	first := installments[0]
+	assert.Equal(t, "8884.88", first.EMI.String())
+	assert.Equal(t, "1000", first.Interest.String())
+	assert.Equal(t, "7884.88", first.Principal.String())
+	assert.Equal(t, "92115.12", first.Balance.String())
+
+	assert.Equal(t, 29, installments[1].Date.Day())
+	assert.Equal(t, 31, installments[2].Date.Day())
+	assert.True(t, installments[11].Balance.IsZero())
+	assert.Equal(t, "100000", total(installments, func(i Installment) decimal.Decimal { return i.Principal }).String())
+	assert.InDelta(t, 8884.88, installments[11].EMI.InexactFloat64(), 0.1)
+}
+
+func TestScheduleRateChange(t *testing.T) {
+	conf := config.Loan{Name: "Car", Account: "Liabilities:Car", Principal: 120000, Rate: 0, Tenure: 12, StartDate: "2024-01-05",
+		RateChanges: []config.LoanRateChange{{Date: "2024-07-01", Rate: 12}}}
+	installments, err := Schedule(conf)
+	assert.NoError(t, err)
+	assert.Len(t, installments, 12)
+	assert.Equal(t, "10000", installments[5].EMI.String())
+	assert.True(t, installments[5].Interest.IsZero())
+	assert.Equal(t, "12", installments[6].Rate.String())
+	assert.Equal(t, "600", installments[6].Interest.String())
+	assert.Equal(t, "10352.9", installments[6].EMI.String())
+
+	conf.EMI = 10000
+	installments, err = Schedule(conf)
+	assert.NoError(t, err)
+	assert.Len(t, installments, 13)
+	assert.Equal(t, "10000", installments[6].EMI.String())
+	assert.True(t, installments[12].EMI.LessThan(decimal.NewFromInt(10000)))
+	assert.True(t, installments[12].Balance.IsZero())
+}
+
+func TestScheduleErrors(t *testing.T) {
+	_, err := Schedule(config.Loan{Name: "Car", Principal: 1000, Rate: 12, Tenure: 12, StartDate: "2024-13-01"})
+	assert.Error(t, err)
+
+	_, err = Schedule(config.Loan{Name: "Car", Principal: 1000, Rate: 12, Tenure: 12, StartDate: "2024-01-01", EMI: 10})
+	assert.Error(t, err)
+}
+
+func TestPostings(t *testing.T) {
+	conf := config.Loan{Name: "Home Loan", Account: "Liabilities:Home", Principal: 1000, Rate: 12, Tenure: 2, StartDate: "2024-01-05"}
+	installments, err := Schedule(conf)
+	assert.NoError(t, err)
+
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, config.TimeZone())
+	until := time.Date(2025, 1, 1, 0, 0, 0, 0, config.TimeZone())
+	postings := Postings(conf, installments, from, until)
+	assert.Len(t, postings, 3)
+	assert.Equal(t, "Liabilities:Home", postings[0].Account)
+	assert.Equal(t, installments[1].Principal.String(), postings[0].Amount.String())
+	assert.Equal(t, "Expenses:Interest:Home", postings[1].Account)
+	assert.Equal(t, installments[1].Interest.String(), postings[1].Amount.String())
+	assert.Equal(t, "Assets:Checking", postings[2].Account)
+	assert.Equal(t, installments[1].EMI.Neg().String(), postings[2].Amount.String())
+	for _, p := range postings {
+		assert.True(t, p.Forecast)
+		assert.Equal(t, "loan:Liabilities:Home:2024-02-05", p.TransactionID)
+	}
+}
@@ -0,0 +1,71 @@
+package cpi
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// CPI is the annual average consumer price index of the country
+type CPI struct {
+	ID      uint            `gorm:"primaryKey" json:"id"`
+	Country string          `json:"country"`
+	Year    int             `json:"year"`
+	Index   decimal.Decimal `json:"index"`
+}
+
+// the consumer price index published by the World Bank (FP.CPI.TOTL,
+// 2010 = 100), used until the index is fetched from the provider
+//
+//go:embed bundled.json
+var bundledJSON []byte
+
+func UpsertAll(db *gorm.DB, country string, cpis []*CPI) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("country = ?", country).Delete(&CPI{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(cpis) > 0 {
+			return tx.Create(cpis).Error
+		}
+		return nil
+	})
+}
+
+// All returns the index of the country ordered by year, the bundled
+// index is returned if the index is not available in the database
+func All(db *gorm.DB, country string) []CPI {
+	var cpis []CPI
+	result := db.Where("country = ?", country).Order("year ASC").Find(&cpis)
+	if result.Error != nil {
+		log.Warn(result.Error)
+	}
+
+	if len(cpis) == 0 {
+		return Bundled(country)
+	}
+	return cpis
+}
+
+func Bundled(country string) []CPI {
+	var bundled map[string][]struct {
+		Year  int             `json:"year"`
+		Index decimal.Decimal `json:"index"`
+	}
+
+	err := json.Unmarshal(bundledJSON, &bundled)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cpis := []CPI{}
+	for _, c := range bundled[country] {
+		cpis = append(cpis, CPI{Country: country, Year: c.Year, Index: c.Index})
+	}
+	return cpis
+}
@@ -0,0 +1,55 @@
+package coin
+
+import (
+	"strings"
+
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/model/price"
+)
+
+type Coin struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	CoinID string
+	Symbol string
+	Name   string
+}
+
+func (Coin) TableName() string {
+	return "crypto_coins"
+}
+
+func Count(db *gorm.DB) int64 {
+	var count int64
+	db.Model(&Coin{}).Count(&count)
+	return count
+}
+
+func UpsertAll(db *gorm.DB, coins []*Coin) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM crypto_coins").Error
+		if err != nil {
+			return err
+		}
+
+		if len(coins) == 0 {
+			return nil
+		}
+
+		return tx.CreateInBatches(coins, 500).Error
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func GetCoinCompletions(db *gorm.DB) []price.AutoCompleteItem {
+	var coins []Coin
+	db.Model(&Coin{}).Order("name").Find(&coins)
+	return lo.Map(coins, func(coin Coin, _ int) price.AutoCompleteItem {
+		return price.AutoCompleteItem{Label: coin.Name + " (" + strings.ToUpper(coin.Symbol) + ")", ID: coin.CoinID}
+	})
+}
@@ -0,0 +1,73 @@
+package journal_file
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JournalFile stores the content hash of a journal file as of the
+// last successful sync
+type JournalFile struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Path      string    `gorm:"uniqueIndex" json:"path"`
+	Hash      string    `json:"hash"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Changed returns the paths that were added, modified or removed
+// since the last sync
+func Changed(db *gorm.DB, hashes map[string]string) ([]string, error) {
+	var files []JournalFile
+	err := db.Find(&files).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return diff(files, hashes), nil
+}
+
+func diff(files []JournalFile, hashes map[string]string) []string {
+	var changed []string
+	stored := make(map[string]bool)
+	for _, file := range files {
+		stored[file.Path] = true
+		if hash, ok := hashes[file.Path]; !ok || hash != file.Hash {
+			changed = append(changed, file.Path)
+		}
+	}
+
+	for path := range hashes {
+		if !stored[path] {
+			changed = append(changed, path)
+		}
+	}
+
+	return changed
+}
+
+func UpsertAll(db *gorm.DB, hashes map[string]string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM journal_files").Error
+		if err != nil {
+			return err
+		}
+
+		for path, hash := range hashes {
+			err := tx.Create(&JournalFile{Path: path, Hash: hash}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteAll forces the next sync to reparse the whole journal
+func DeleteAll(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&JournalFile{}) {
+		return nil
+	}
+	return db.Exec("DELETE FROM journal_files").Error
+}
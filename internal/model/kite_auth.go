@@ -4,16 +4,22 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/secrets"
 )
 
-// KiteAuth stores Kite Connect authentication data for each account
+// KiteAuth stores Kite Connect authentication data for each account. The
+// tokens are stored encrypted, rows written by older versions in
+// plaintext are still readable.
 type KiteAuth struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	APIKey       string    `json:"api_key" gorm:"uniqueIndex"` // Added to support multiple accounts
-	RequestToken string    `json:"request_token"`
-	AccessToken  string    `json:"access_token"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	APIKey        string    `json:"api_key" gorm:"uniqueIndex"` // Added to support multiple accounts
+	RequestToken  string    `json:"request_token"`
+	AccessToken   string    `json:"access_token"`
+	AccessTokenAt time.Time `json:"access_token_at"` // When the current access token was issued
+	LastError     string    `json:"last_error"`      // Error of the last failed login
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // TableName specifies the table name for KiteAuth
@@ -21,6 +27,18 @@ func (KiteAuth) TableName() string {
 	return "kite_auth"
 }
 
+// decryptTokens replaces the stored tokens with their plaintext values
+func (auth *KiteAuth) decryptTokens() error {
+	var err error
+	auth.RequestToken, err = secrets.Resolve(auth.RequestToken)
+	if err != nil {
+		return err
+	}
+
+	auth.AccessToken, err = secrets.Resolve(auth.AccessToken)
+	return err
+}
+
 // GetAuthByAPIKey retrieves authentication data for a specific API key
 func GetAuthByAPIKey(db *gorm.DB, apiKey string) (*KiteAuth, error) {
 	var auth KiteAuth
@@ -31,6 +49,9 @@ func GetAuthByAPIKey(db *gorm.DB, apiKey string) (*KiteAuth, error) {
 		}
 		return nil, err
 	}
+	if err := auth.decryptTokens(); err != nil {
+		return nil, err
+	}
 	return &auth, nil
 }
 
@@ -44,11 +65,19 @@ func GetLatestAuth(db *gorm.DB) (*KiteAuth, error) {
 		}
 		return nil, err
 	}
+	if err := auth.decryptTokens(); err != nil {
+		return nil, err
+	}
 	return &auth, nil
 }
 
 // StoreRequestToken stores a new request token for a specific API key
 func StoreRequestToken(db *gorm.DB, apiKey string, requestToken string) error {
+	requestToken, err := secrets.Encrypt(requestToken)
+	if err != nil {
+		return err
+	}
+
 	// Use Upsert to either update existing entry or create new one
 	var auth KiteAuth
 	result := db.Where("api_key = ?", apiKey).First(&auth)
@@ -82,9 +111,28 @@ func UpdateAccessToken(db *gorm.DB, apiKey string, accessToken string) error {
 		return gorm.ErrRecordNotFound
 	}
 
+	accessToken, err = secrets.Encrypt(accessToken)
+	if err != nil {
+		return err
+	}
+
 	// Update the specific record
 	return db.Model(auth).
-		Update("access_token", accessToken).Error
+		Updates(map[string]any{"access_token": accessToken, "access_token_at": time.Now(), "last_error": ""}).Error
+}
+
+// StoreAuthError records the error of a failed login for a specific API key
+func StoreAuthError(db *gorm.DB, apiKey string, message string) error {
+	var auth KiteAuth
+	result := db.Where("api_key = ?", apiKey).First(&auth)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return db.Create(&KiteAuth{APIKey: apiKey, LastError: message}).Error
+		}
+		return result.Error
+	}
+
+	return db.Model(&auth).Update("last_error", message).Error
 }
 
 // ClearAuth clears all authentication data
@@ -3,23 +3,35 @@ package model
 import (
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/ananthakumaran/paisa/internal/cache"
 	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/events"
 	"github.com/ananthakumaran/paisa/internal/ledger"
-	"github.com/ananthakumaran/paisa/internal/model/cache"
+	"github.com/ananthakumaran/paisa/internal/loan"
+	cacheModel "github.com/ananthakumaran/paisa/internal/model/cache"
 	"github.com/ananthakumaran/paisa/internal/model/cii"
 	"github.com/ananthakumaran/paisa/internal/model/commodity"
+	"github.com/ananthakumaran/paisa/internal/model/cpi"
+	"github.com/ananthakumaran/paisa/internal/model/crypto/coin"
+	"github.com/ananthakumaran/paisa/internal/model/journal_file"
 	mutualfundModel "github.com/ananthakumaran/paisa/internal/model/mutualfund/scheme"
 	npsModel "github.com/ananthakumaran/paisa/internal/model/nps/scheme"
 	"github.com/ananthakumaran/paisa/internal/model/portfolio"
 	"github.com/ananthakumaran/paisa/internal/model/posting"
 	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/ananthakumaran/paisa/internal/model/statement"
 	"github.com/ananthakumaran/paisa/internal/model/stock_tag"
 	"github.com/ananthakumaran/paisa/internal/model/stock_target_price"
 	"github.com/ananthakumaran/paisa/internal/model/task_execution"
 	"github.com/ananthakumaran/paisa/internal/scraper"
+	"github.com/ananthakumaran/paisa/internal/scraper/fx"
 	"github.com/ananthakumaran/paisa/internal/scraper/india"
 	"github.com/ananthakumaran/paisa/internal/scraper/mutualfund"
+	"github.com/ananthakumaran/paisa/internal/scraper/worldbank"
+	"github.com/ananthakumaran/paisa/internal/utils"
 	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -28,24 +40,80 @@ import (
 func AutoMigrate(db *gorm.DB) {
 	db.AutoMigrate(&npsModel.Scheme{})
 	db.AutoMigrate(&mutualfundModel.Scheme{})
+	db.AutoMigrate(&coin.Coin{})
 	db.AutoMigrate(&posting.Posting{})
 	db.AutoMigrate(&price.Price{})
 	db.AutoMigrate(&portfolio.Portfolio{})
 	db.AutoMigrate(&price.Price{})
 	db.AutoMigrate(&cii.CII{})
-	db.AutoMigrate(&cache.Cache{})
+	db.AutoMigrate(&cpi.CPI{})
+	db.AutoMigrate(&cacheModel.Cache{})
 	db.AutoMigrate(&stock_target_price.StockTargetPrice{})
 	db.AutoMigrate(&stock_tag.StockTag{})
 	db.AutoMigrate(&stock_tag.StockTagAssociation{})
 	db.AutoMigrate(&task_execution.TaskExecution{})
 	db.AutoMigrate(&KiteAuth{})
+	db.AutoMigrate(&journal_file.JournalFile{})
+	db.AutoMigrate(&statement.Statement{})
 }
 
+// syncJournalMu serializes the syncs triggered by the user and the
+// journal watcher
+var syncJournalMu sync.Mutex
+
 func SyncJournal(db *gorm.DB) (string, error) {
+	syncJournalMu.Lock()
+	defer syncJournalMu.Unlock()
+
 	AutoMigrate(db)
 	log.Info("Syncing transactions from journal")
 
-	errors, _, err := ledger.Cli().ValidateFile(config.GetJournalPath())
+	journalPath := config.GetJournalPath()
+	hashes, err := ledger.Fingerprint(journalPath)
+	if err != nil {
+		return err.Error(), err
+	}
+
+	changed, err := journal_file.Changed(db, hashes)
+	if err != nil {
+		return err.Error(), err
+	}
+
+	if len(changed) == 0 {
+		log.Info("Journal is unchanged since the last sync")
+		err = syncLoans(db)
+		cache.Clear()
+		if err != nil {
+			return err.Error(), err
+		}
+		return "", nil
+	}
+
+	message, err := syncFiles(db, journalPath, hashes, changed)
+	if err == nil {
+		err = syncLoans(db)
+		if err != nil {
+			message = err.Error()
+		}
+	}
+
+	// the clients refetch on the event, which should not be served
+	// from the stale cache
+	cache.Clear()
+	events.Publish(events.JournalSynced, events.JournalSyncData{
+		Files:   lo.Without(changed, ledger.ContextFile),
+		Success: err == nil,
+		Message: message,
+	})
+	return message, err
+}
+
+// syncFiles updates the postings of the changed files, all the postings
+// are updated if the changes affect the unchanged files as well. The
+// journal is always validated and parsed as a whole, only the postings
+// written to the database are limited to the changed files.
+func syncFiles(db *gorm.DB, journalPath string, hashes map[string]string, changed []string) (string, error) {
+	errors, _, err := ledger.Cli().ValidateFile(journalPath)
 	if err != nil {
 
 		if len(errors) == 0 {
@@ -59,45 +127,196 @@ func SyncJournal(db *gorm.DB) (string, error) {
 		return strings.TrimRight(message, "\n"), err
 	}
 
-	prices, err := ledger.Cli().Prices(config.GetJournalPath())
+	prices, err := ledger.Cli().Prices(journalPath)
 	if err != nil {
 		return err.Error(), err
 	}
 
-	price.UpsertAllByType(db, config.Unknown, prices)
+	pricesUnchanged, err := price.EqualByType(db, config.Unknown, prices)
+	if err != nil {
+		return err.Error(), err
+	}
+
+	// the postings of the unchanged files can be reused only if
+	// nothing else affecting them has changed
+	var fileNames []string
+	if pricesUnchanged && !lo.Contains(changed, ledger.ContextFile) && ledger.HasFileStableIDs(ledger.Cli()) {
+		fileNames = changed
+		log.Infof("Syncing changed journal files: %s", strings.Join(changed, ", "))
+	} else {
+		price.UpsertAllByType(db, config.Unknown, prices)
+	}
+
+	var postings []*posting.Posting
+	if incremental, ok := ledger.Cli().(ledger.IncrementalLedger); ok && fileNames != nil {
+		postings, err = incremental.ParseFiles(journalPath, prices, fileNames)
+	} else {
+		postings, err = ledger.Cli().Parse(journalPath, prices)
+		if fileNames != nil {
+			postings = lo.Filter(postings, func(p *posting.Posting, _ int) bool {
+				return lo.Contains(fileNames, p.FileName)
+			})
+		}
+	}
+	if err != nil {
+		return err.Error(), err
+	}
+
+	err = posting.UpsertFiles(db, postings, fileNames)
+	if err != nil {
+		return err.Error(), err
+	}
 
-	postings, err := ledger.Cli().Parse(config.GetJournalPath(), prices)
+	err = journal_file.UpsertAll(db, hashes)
 	if err != nil {
 		return err.Error(), err
 	}
-	posting.UpsertAll(db, postings)
 
 	return "", nil
 }
 
+// syncLoans replaces the forecast transactions generated from the
+// loan schedules, over the same range as the periodic transactions
+// of the journal
+func syncLoans(db *gorm.DB) error {
+	now := utils.Now()
+	from := time.Date(now.Year()-3, 1, 1, 0, 0, 0, 0, config.TimeZone())
+	until := time.Date(now.Year()+3, 1, 1, 0, 0, 0, 0, config.TimeZone())
+
+	var postings []*posting.Posting
+	for _, conf := range config.GetConfig().Loans {
+		// the invalid loans are reported by the doctor
+		installments, err := loan.Schedule(conf)
+		if err != nil {
+			log.Warnf("Skipping the forecast of loan %s: %v", conf.Name, err)
+			continue
+		}
+		postings = append(postings, loan.Postings(conf, installments, from, until)...)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("forecast = ? AND transaction_id LIKE ?", true, "loan:%").Delete(&posting.Posting{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(postings) > 0 {
+			return tx.CreateInBatches(postings, 100).Error
+		}
+		return nil
+	})
+}
+
+// the providers are rate limited individually, this only bounds the
+// number of requests in flight across all the providers
+const maxParallelFetches = 4
+
+var (
+	priceFetchErrors   = make(map[string]string)
+	priceFetchErrorsMu sync.Mutex
+)
+
+// PriceFetchErrors returns the error of the last price fetch keyed by
+// the commodity name, only the commodities which failed are included
+func PriceFetchErrors() map[string]string {
+	priceFetchErrorsMu.Lock()
+	defer priceFetchErrorsMu.Unlock()
+	return lo.Assign(priceFetchErrors)
+}
+
+func setPriceFetchError(commodityName string, err error) {
+	priceFetchErrorsMu.Lock()
+	defer priceFetchErrorsMu.Unlock()
+	if err == nil {
+		delete(priceFetchErrors, commodityName)
+	} else {
+		priceFetchErrors[commodityName] = err.Error()
+	}
+}
+
+type commodityFetch struct {
+	commodity config.Commodity
+	provider  price.PriceProvider
+	// since is zero if the whole history has to be fetched
+	since  time.Time
+	prices []*price.Price
+	err    error
+}
+
 func SyncCommodities(db *gorm.DB) error {
 	AutoMigrate(db)
 	log.Info("Fetching commodities price history")
-	commodities := lo.Shuffle(commodity.All())
+	commodities := lo.Shuffle(append(commodity.All(), fxCommodities(db)...))
 
 	var errors []error
+	fetches := make([]commodityFetch, 0, len(commodities))
 	for _, commodity := range commodities {
-		name := commodity.Name
-		log.Info("Fetching commodity ", name)
-		code := commodity.Price.Code
-		var prices []*price.Price
-		var err error
-
-		provider := scraper.GetProviderByCode(commodity.Price.Provider)
-		prices, err = provider.GetPrices(code, name)
-
+		provider, err := scraper.GetProviderByCode(commodity.Price.Provider)
 		if err != nil {
 			log.Error(err)
-			errors = append(errors, fmt.Errorf("Failed to fetch price for %s: %w", name, err))
+			setPriceFetchError(commodity.Name, err)
+			errors = append(errors, fmt.Errorf("Failed to fetch price for %s: %w", commodity.Name, err))
 			continue
 		}
 
-		price.UpsertAllByTypeNameAndID(db, commodity.Type, name, code, prices)
+		fetch := commodityFetch{commodity: commodity, provider: provider}
+		if _, ok := provider.(price.IncrementalPriceProvider); ok {
+			since, err := price.LastDateByTypeNameAndID(db, commodity.Type, commodity.Name, commodity.Price.Code)
+			if err != nil {
+				log.Error(err)
+			}
+			fetch.since = since
+		}
+		fetches = append(fetches, fetch)
+	}
+
+	jobs := make(chan commodityFetch)
+	results := make(chan commodityFetch)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(maxParallelFetches, len(fetches)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for fetch := range jobs {
+				commodity := fetch.commodity
+				if incremental, ok := fetch.provider.(price.IncrementalPriceProvider); ok && !fetch.since.IsZero() {
+					log.Infof("Fetching commodity %s since %s", commodity.Name, fetch.since.Format("2006-01-02"))
+					fetch.prices, fetch.err = incremental.GetPricesSince(commodity.Price.Code, commodity.Name, fetch.since)
+				} else {
+					log.Info("Fetching commodity ", commodity.Name)
+					fetch.prices, fetch.err = fetch.provider.GetPrices(commodity.Price.Code, commodity.Name)
+				}
+				results <- fetch
+			}
+		}()
+	}
+
+	go func() {
+		for _, fetch := range fetches {
+			jobs <- fetch
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	// the database writes are done sequentially as sqlite allows only
+	// one writer at a time
+	for result := range results {
+		commodity := result.commodity
+		setPriceFetchError(commodity.Name, result.err)
+		if result.err != nil {
+			log.Error(result.err)
+			errors = append(errors, fmt.Errorf("Failed to fetch price for %s: %w", commodity.Name, result.err))
+			continue
+		}
+
+		if result.since.IsZero() {
+			price.UpsertAllByTypeNameAndID(db, commodity.Type, commodity.Name, commodity.Price.Code, result.prices)
+		} else {
+			price.AppendAllByTypeNameAndID(db, commodity.Type, commodity.Name, commodity.Price.Code, result.since, result.prices)
+		}
 	}
 
 	if len(errors) > 0 {
@@ -110,6 +329,32 @@ func SyncCommodities(db *gorm.DB) error {
 	return nil
 }
 
+// fxCommodities returns the currencies used in the journal which are not
+// configured as commodities, if the exchange rates have to be filled
+// automatically
+func fxCommodities(db *gorm.DB) []config.Commodity {
+	if config.GetConfig().FX.AutoFill != config.Yes {
+		return nil
+	}
+
+	var names []string
+	err := db.Model(&posting.Posting{}).Distinct().Pluck("commodity", &names).Error
+	if err != nil {
+		log.Error(err)
+		return nil
+	}
+
+	var commodities []config.Commodity
+	for _, name := range names {
+		if utils.IsCurrency(name) || !fx.IsCurrencyCode(name) || commodity.FindByName(name).Name != "" {
+			continue
+		}
+
+		commodities = append(commodities, config.Commodity{Name: name, Type: config.Currency, Price: config.Price{Provider: "fx", Code: name}})
+	}
+	return commodities
+}
+
 func SyncCII(db *gorm.DB) error {
 	AutoMigrate(db)
 	log.Info("Fetching taxation related info")
@@ -122,6 +367,17 @@ func SyncCII(db *gorm.DB) error {
 	return nil
 }
 
+func SyncCPI(db *gorm.DB) error {
+	db.AutoMigrate(&cpi.CPI{})
+	country := config.GetConfig().CPI.Country
+	cpis, err := worldbank.GetConsumerPriceIndex(country)
+	if err != nil {
+		log.Error(err)
+		return fmt.Errorf("Failed to fetch CPI: %w", err)
+	}
+	return cpi.UpsertAll(db, country, cpis)
+}
+
 func SyncPortfolios(db *gorm.DB) error {
 	db.AutoMigrate(&portfolio.Portfolio{})
 	log.Info("Fetching commodities portfolio")
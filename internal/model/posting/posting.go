@@ -1,10 +1,12 @@
 package posting
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ananthakumaran/paisa/internal/utils"
+	"github.com/samber/lo"
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -114,25 +116,90 @@ func (p Posting) HasBehaviour(behaviour string) bool {
 	return false
 }
 
-func UpsertAll(db *gorm.DB, postings []*Posting) {
-	err := db.Transaction(func(tx *gorm.DB) error {
-		err := tx.Exec("DELETE FROM postings").Error
+// UpsertFiles replaces the postings of the given files, only the
+// postings that have changed are deleted or inserted. All the postings
+// are replaced if fileNames is nil.
+func UpsertFiles(db *gorm.DB, postings []*Posting, fileNames []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing []*Posting
+		query := tx
+		if fileNames != nil {
+			query = query.Where("file_name IN ?", fileNames)
+		}
+		err := query.Find(&existing).Error
 		if err != nil {
 			return err
 		}
-		for _, posting := range postings {
-			err := tx.Create(posting).Error
+
+		inserts, deletes := diff(existing, postings)
+		log.Infof("Updating postings, %d deleted, %d inserted, %d unchanged", len(deletes), len(inserts), len(existing)-len(deletes))
+
+		for _, ids := range lo.Chunk(deletes, 500) {
+			err := tx.Delete(&Posting{}, ids).Error
 			if err != nil {
 				return err
 			}
 		}
 
+		if len(inserts) > 0 {
+			return tx.CreateInBatches(inserts, 100).Error
+		}
+
 		return nil
 	})
+}
 
-	if err != nil {
-		log.Fatal(err)
+// diff returns the postings to insert and the ids of the existing
+// postings to delete
+func diff(existing []*Posting, postings []*Posting) ([]*Posting, []uint) {
+	ids := make(map[string][]uint)
+	for _, p := range existing {
+		key := p.key()
+		ids[key] = append(ids[key], p.ID)
 	}
+
+	var inserts []*Posting
+	for _, p := range postings {
+		key := p.key()
+		if len(ids[key]) > 0 {
+			ids[key] = ids[key][1:]
+			continue
+		}
+		inserts = append(inserts, p)
+	}
+
+	var deletes []uint
+	for _, p := range existing {
+		key := p.key()
+		if len(ids[key]) > 0 {
+			deletes = append(deletes, ids[key][0])
+			ids[key] = ids[key][1:]
+		}
+	}
+
+	return inserts, deletes
+}
+
+// key identifies the posting by all the persisted fields except ID
+func (p *Posting) key() string {
+	return strings.Join([]string{
+		p.TransactionID,
+		strconv.FormatInt(p.Date.UnixNano(), 10),
+		p.Payee,
+		p.Account,
+		p.Commodity,
+		p.Quantity.String(),
+		p.Amount.String(),
+		p.Status,
+		p.TagRecurring,
+		p.TagPeriod,
+		strconv.FormatUint(p.TransactionBeginLine, 10),
+		strconv.FormatUint(p.TransactionEndLine, 10),
+		p.FileName,
+		strconv.FormatBool(p.Forecast),
+		p.Note,
+		p.TransactionNote,
+	}, "\x00")
 }
 
 func Behaviours(account string) []string {
@@ -0,0 +1,32 @@
+package posting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	date := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	rent := Posting{Date: date, Account: "Expenses:Rent", Amount: decimal.NewFromInt(5000), FileName: "main.ledger"}
+	checking := Posting{Date: date, Account: "Assets:Checking", Amount: decimal.NewFromInt(-5000), FileName: "main.ledger"}
+
+	existing := []*Posting{{ID: 1}, {ID: 2}, {ID: 3}}
+	*existing[0] = rent
+	existing[0].ID = 1
+	*existing[1] = checking
+	existing[1].ID = 2
+	*existing[2] = checking
+	existing[2].ID = 3
+
+	updated := rent
+	updated.Amount = decimal.RequireFromString("5000.00")
+	fee := checking
+	fee.Amount = decimal.NewFromInt(-10)
+
+	inserts, deletes := diff(existing, []*Posting{&updated, &checking, &fee})
+	assert.Equal(t, []*Posting{&fee}, inserts)
+	assert.Equal(t, []uint{3}, deletes)
+}
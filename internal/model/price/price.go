@@ -1,12 +1,14 @@
 package price
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/google/btree"
+	"github.com/samber/lo"
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 )
@@ -54,6 +56,80 @@ func UpsertAllByTypeNameAndID(db *gorm.DB, commodityType config.CommodityType, c
 	}
 }
 
+// LastDateByTypeNameAndID returns the date of the latest stored price
+// of the commodity, zero if there are no prices
+func LastDateByTypeNameAndID(db *gorm.DB, commodityType config.CommodityType, commodityName string, commodityID string) (time.Time, error) {
+	var prices []Price
+	err := db.Where("commodity_type = ? and commodity_id = ? and commodity_name = ?", commodityType, commodityID, commodityName).Order("date DESC").Limit(1).Find(&prices).Error
+	if err != nil || len(prices) == 0 {
+		return time.Time{}, err
+	}
+	return prices[0].Date, nil
+}
+
+// AppendAllByTypeNameAndID replaces the prices of the commodity on or
+// after the since date, the older prices are left untouched. Nothing is
+// replaced if the provider didn't return any new price.
+func AppendAllByTypeNameAndID(db *gorm.DB, commodityType config.CommodityType, commodityName string, commodityID string, since time.Time, prices []*Price) {
+	prices = lo.Filter(prices, func(price *Price, _ int) bool { return !price.Date.Before(since) })
+	if len(prices) == 0 {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Delete(&Price{}, "commodity_type = ? and commodity_id = ? and commodity_name = ? and date >= ?", commodityType, commodityID, commodityName, since).Error
+		if err != nil {
+			return err
+		}
+
+		for _, price := range prices {
+			err := tx.Create(price).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// EqualByType checks whether the stored prices of the type are same as
+// the given prices, the order is ignored
+func EqualByType(db *gorm.DB, commodityType config.CommodityType, prices []Price) (bool, error) {
+	var existing []Price
+	err := db.Where("commodity_type = ?", commodityType).Find(&existing).Error
+	if err != nil {
+		return false, err
+	}
+
+	if len(existing) != len(prices) {
+		return false, nil
+	}
+
+	counts := make(map[string]int)
+	for _, p := range existing {
+		counts[p.key()]++
+	}
+
+	for _, p := range prices {
+		key := p.key()
+		if counts[key] == 0 {
+			return false, nil
+		}
+		counts[key]--
+	}
+
+	return true, nil
+}
+
+func (p Price) key() string {
+	return fmt.Sprintf("%d:%s:%s:%s", p.Date.UnixNano(), p.CommodityID, p.CommodityName, p.Value.String())
+}
+
 func UpsertAllByType(db *gorm.DB, commodityType config.CommodityType, prices []Price) {
 	err := db.Transaction(func(tx *gorm.DB) error {
 		err := tx.Delete(&Price{}, "commodity_type = ?", commodityType).Error
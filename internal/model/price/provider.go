@@ -1,6 +1,10 @@
 package price
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type AutoCompleteItem struct {
 	Label string `json:"label"`
@@ -23,3 +27,11 @@ type PriceProvider interface {
 	ClearCache(db *gorm.DB)
 	GetPrices(code string, commodityName string) ([]*Price, error)
 }
+
+// IncrementalPriceProvider is implemented by the providers which can
+// fetch the prices from a date instead of the whole history. The
+// prices returned should include the since date.
+type IncrementalPriceProvider interface {
+	PriceProvider
+	GetPricesSince(code string, commodityName string, since time.Time) ([]*Price, error)
+}
@@ -0,0 +1,41 @@
+package statement
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Statement is the closing balance of an account as per the bank
+// statement on the given date
+type Statement struct {
+	ID      uint            `gorm:"primaryKey" json:"id"`
+	Account string          `gorm:"uniqueIndex:idx_statement_account_date" json:"account"`
+	Date    time.Time       `gorm:"uniqueIndex:idx_statement_account_date" json:"date"`
+	Balance decimal.Decimal `gorm:"type:decimal(20,8)" json:"balance"`
+}
+
+func All(db *gorm.DB) ([]Statement, error) {
+	var statements []Statement
+	err := db.Order("account asc, date desc").Find(&statements).Error
+	return statements, err
+}
+
+// Upsert replaces the statement of the account on the same date
+func Upsert(db *gorm.DB, statement Statement) (Statement, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("account = ? and date = ?", statement.Account, statement.Date).Delete(&Statement{}).Error
+		if err != nil {
+			return err
+		}
+
+		statement.ID = 0
+		return tx.Create(&statement).Error
+	})
+	return statement, err
+}
+
+func Delete(db *gorm.DB, id uint) error {
+	return db.Delete(&Statement{}, id).Error
+}
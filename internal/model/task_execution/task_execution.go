@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/calendar"
 )
 
 // TaskExecution tracks when tasks were last executed
@@ -159,8 +161,11 @@ func UpdateLastSuccessfulRun(db *gorm.DB, taskName string) error {
 	})
 }
 
-// ShouldRunToday checks if a task should run today based on its last successful execution
-func ShouldRunToday(db *gorm.DB, taskName string) (bool, error) {
+// ShouldRunToday checks if a task should run today based on its last
+// successful execution. If exchange is not empty, the run is only
+// considered missed if the task has not succeeded since the last
+// trading day of the exchange.
+func ShouldRunToday(db *gorm.DB, taskName string, exchange string) (bool, error) {
 	lastSuccessfulRun, err := GetLastSuccessfulRun(db, taskName)
 	if err != nil {
 		return false, err
@@ -176,5 +181,9 @@ func ShouldRunToday(db *gorm.DB, taskName string) (bool, error) {
 	lastRunDate := time.Date(lastSuccessfulRun.Year(), lastSuccessfulRun.Month(), lastSuccessfulRun.Day(), 0, 0, 0, 0, lastSuccessfulRun.Location())
 	todayDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 
+	if exchange != "" {
+		todayDate = calendar.LastTradingDay(exchange, now)
+	}
+
 	return lastRunDate.Before(todayDate), nil
 }
@@ -0,0 +1,145 @@
+package transaction
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+)
+
+// DuplicateOptions controls how close two transactions should be to be
+// considered duplicates
+type DuplicateOptions struct {
+	// Window is the maximum number of days between the transactions,
+	// imports usually use the value date while the hand entries use
+	// the transaction date
+	Window int
+	// PayeeSimilarity is the minimum similarity of the payees, between
+	// 0 and 1
+	PayeeSimilarity float64
+}
+
+var DefaultDuplicateOptions = DuplicateOptions{Window: 3, PayeeSimilarity: 0.6}
+
+type DuplicatePair struct {
+	First      Transaction
+	Second     Transaction
+	Similarity float64
+}
+
+// FindDuplicates returns the pairs of transactions within the date
+// window which have the same total, share at least one posting with the
+// same account and amount and have similar payees
+func FindDuplicates(transactions []Transaction, options DuplicateOptions) []DuplicatePair {
+	transactions = lo.Filter(transactions, func(t Transaction, _ int) bool { return len(t.Postings) > 0 })
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if transactions[i].Date.Equal(transactions[j].Date) {
+			return transactions[i].FileName < transactions[j].FileName ||
+				(transactions[i].FileName == transactions[j].FileName && transactions[i].BeginLine < transactions[j].BeginLine)
+		}
+		return transactions[i].Date.Before(transactions[j].Date)
+	})
+
+	window := time.Duration(options.Window) * 24 * time.Hour
+	pairs := []DuplicatePair{}
+	for i, first := range transactions {
+		for _, second := range transactions[i+1:] {
+			if second.Date.Sub(first.Date) > window {
+				break
+			}
+
+			if !sameAmounts(first, second) {
+				continue
+			}
+
+			similarity := PayeeSimilarity(first.Payee, second.Payee)
+			if similarity >= options.PayeeSimilarity {
+				pairs = append(pairs, DuplicatePair{First: first, Second: second, Similarity: similarity})
+			}
+		}
+	}
+	return pairs
+}
+
+func sameAmounts(a, b Transaction) bool {
+	if !total(a).Equal(total(b)) {
+		return false
+	}
+
+	return lo.SomeBy(a.Postings, func(pa posting.Posting) bool {
+		return lo.SomeBy(b.Postings, func(pb posting.Posting) bool {
+			return pa.Account == pb.Account && pa.Amount.Equal(pb.Amount)
+		})
+	})
+}
+
+func total(t Transaction) decimal.Decimal {
+	sum := decimal.Zero
+	for _, p := range t.Postings {
+		if p.Amount.IsPositive() {
+			sum = sum.Add(p.Amount)
+		}
+	}
+	return sum
+}
+
+// PayeeSimilarity is the higher of the edit distance similarity and the
+// fraction of the words of the shorter payee present in the other.
+// Bank statements usually wrap the merchant name with references like
+// UPI/412345/SWIGGY, which the word match handles.
+func PayeeSimilarity(a, b string) float64 {
+	wordsA, wordsB := payeeWords(a), payeeWords(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	normalizedA, normalizedB := strings.Join(wordsA, " "), strings.Join(wordsB, " ")
+	longest := max(len([]rune(normalizedA)), len([]rune(normalizedB)))
+	editSimilarity := 1 - float64(levenshtein(normalizedA, normalizedB))/float64(longest)
+
+	common := len(lo.Intersect(lo.Uniq(wordsA), lo.Uniq(wordsB)))
+	wordSimilarity := float64(common) / float64(min(len(lo.Uniq(wordsA)), len(lo.Uniq(wordsB))))
+
+	return max(editSimilarity, wordSimilarity)
+}
+
+// words common in the bank statement narrations, which would make
+// unrelated payees look similar
+var payeeStopWords = []string{"upi", "neft", "imps", "rtgs", "nach", "ach", "pos", "atm", "transfer", "payment", "to", "from", "by", "the", "ltd", "pvt"}
+
+// payeeWords splits the payee into lowercase words, ignoring the
+// numbers which are usually references
+func payeeWords(payee string) []string {
+	return lo.Filter(strings.FieldsFunc(strings.ToLower(payee), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), func(word string, _ int) bool {
+		return strings.IndexFunc(word, unicode.IsLetter) != -1 && !lo.Contains(payeeStopWords, word)
+	})
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
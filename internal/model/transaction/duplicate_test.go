@@ -0,0 +1,49 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+)
+
+func duplicateTransaction(id string, date string, payee string, amount float64, expense string) Transaction {
+	d, _ := time.Parse("2006-01-02", date)
+	return Transaction{
+		ID:    id,
+		Date:  d,
+		Payee: payee,
+		Postings: []posting.Posting{
+			{Account: expense, Amount: decimal.NewFromFloat(amount)},
+			{Account: "Assets:Checking", Amount: decimal.NewFromFloat(-amount)},
+		},
+		FileName:  "main.ledger",
+		BeginLine: 1,
+	}
+}
+
+func TestPayeeSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, PayeeSimilarity("UPI/412345/SWIGGY", "Swiggy"))
+	assert.Equal(t, 1.0, PayeeSimilarity("Amazon", "amazon"))
+	assert.InDelta(t, 0.83, PayeeSimilarity("Amazon", "Amazn"), 0.01)
+	assert.Less(t, PayeeSimilarity("UPI Transfer Swiggy", "UPI Transfer Zomato"), 0.6)
+	assert.Equal(t, 0.0, PayeeSimilarity("123456", "Swiggy"))
+}
+
+func TestFindDuplicates(t *testing.T) {
+	transactions := []Transaction{
+		duplicateTransaction("1", "2023-01-01", "Swiggy", 450, "Expenses:Food"),
+		duplicateTransaction("2", "2023-01-03", "UPI/412345/SWIGGY", 450, "Expenses:Unknown"),
+		duplicateTransaction("3", "2023-01-03", "Zomato", 450, "Expenses:Food"),
+		duplicateTransaction("4", "2023-01-10", "Swiggy", 450, "Expenses:Food"),
+		duplicateTransaction("5", "2023-01-02", "Swiggy", 460, "Expenses:Food"),
+	}
+
+	pairs := FindDuplicates(transactions, DefaultDuplicateOptions)
+	assert.Len(t, pairs, 1)
+	assert.Equal(t, "1", pairs[0].First.ID)
+	assert.Equal(t, "2", pairs[0].Second.ID)
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// desktopTimeout limits how long the caller waits for the handler. The
+// dialog of the desktop app stays open until it is dismissed, which
+// should not hold up the background tasks.
+var desktopTimeout = 5 * time.Second
+
+var (
+	desktopHandler   func(notification Notification) error
+	desktopHandlerMu sync.Mutex
+)
+
+// SetDesktopHandler is called by the desktop app to show the
+// notifications natively
+func SetDesktopHandler(handler func(notification Notification) error) {
+	desktopHandlerMu.Lock()
+	defer desktopHandlerMu.Unlock()
+	desktopHandler = handler
+}
+
+// DesktopNotifier shows the notification in the desktop app, it fails
+// if paisa is not running as a desktop app.
+type DesktopNotifier struct{}
+
+func (n *DesktopNotifier) Notify(ctx context.Context, notification Notification) error {
+	desktopHandlerMu.Lock()
+	handler := desktopHandler
+	desktopHandlerMu.Unlock()
+
+	if handler == nil {
+		return fmt.Errorf("desktop notifications are only available in the desktop app")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- handler(notification)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(desktopTimeout):
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+func post(ctx context.Context, url string, token string, contentType string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// WebhookNotifier posts the notification as JSON to the URL
+type WebhookNotifier struct {
+	URL   string
+	Token string
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, n.URL, n.Token, "application/json", body, nil)
+}
+
+// NtfyNotifier publishes the notification to a ntfy topic, the URL
+// should include the topic, like https://ntfy.sh/paisa
+type NtfyNotifier struct {
+	URL   string
+	Token string
+}
+
+func (n *NtfyNotifier) Notify(ctx context.Context, notification Notification) error {
+	headers := map[string]string{
+		"Title": notification.Title,
+		"Tags":  notification.Event,
+	}
+	if notification.Event == EventTaskFailure || notification.Event == EventKiteLogin {
+		headers["Priority"] = "high"
+	}
+
+	return post(ctx, n.URL, n.Token, "text/plain", []byte(notification.Message), headers)
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/events"
+	"github.com/ananthakumaran/paisa/internal/secrets"
+)
+
+const (
+	EventTaskFailure = "task_failure"
+	EventKiteLogin   = "kite_login"
+	EventPriceAlert  = "price_alert"
+	EventBudget      = "budget"
+)
+
+type Notification struct {
+	Event   string    `json:"event"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Build creates the notifier described by the config
+func Build(cfg config.Notifier) (Notifier, error) {
+	switch cfg.Type {
+	case "smtp":
+		password, err := secrets.Resolve(cfg.Password)
+		if err != nil {
+			return nil, err
+		}
+		return &SMTPNotifier{Host: cfg.Host, Port: cfg.Port, Username: cfg.Username, Password: password, From: cfg.From, To: cfg.To}, nil
+	case "webhook", "ntfy":
+		token, err := secrets.Resolve(cfg.Token)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Type == "ntfy" {
+			return &NtfyNotifier{URL: cfg.URL, Token: token}, nil
+		}
+		return &WebhookNotifier{URL: cfg.URL, Token: token}, nil
+	case "desktop":
+		return &DesktopNotifier{}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %s", cfg.Type)
+	}
+}
+
+func subscribed(cfg config.Notifier, event string) bool {
+	return len(cfg.Events) == 0 || lo.Contains(cfg.Events, event)
+}
+
+// Send delivers the notification through all the notifiers configured
+// for the event. Failures are logged, a broken notifier should not
+// affect the caller.
+func Send(ctx context.Context, event string, title string, message string) {
+	notification := Notification{Event: event, Title: title, Message: message, Time: time.Now()}
+	events.Publish(events.AlertRaised, events.AlertData{Event: event, Title: title, Message: message})
+
+	for _, cfg := range config.GetConfig().Notifiers {
+		if !subscribed(cfg, event) {
+			continue
+		}
+
+		notifier, err := Build(cfg)
+		if err == nil {
+			err = notifier.Notify(ctx, notification)
+		}
+
+		if err != nil {
+			log.Warnf("Failed to send notification through %s: %v", cfg.Name, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+)
+
+var notification = Notification{Event: EventTaskFailure, Title: "Background task failed", Message: "network is down", Time: time.Now()}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Notification
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	notifier, err := Build(config.Notifier{Type: "webhook", URL: server.URL, Token: "secret"})
+	assert.NoError(t, err)
+	assert.NoError(t, notifier.Notify(context.Background(), notification))
+	assert.Equal(t, "Bearer secret", authorization)
+	assert.Equal(t, notification.Title, received.Title)
+	assert.Equal(t, notification.Message, received.Message)
+}
+
+func TestNtfyNotifier(t *testing.T) {
+	var title, priority, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		title = r.Header.Get("Title")
+		priority = r.Header.Get("Priority")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	notifier, err := Build(config.Notifier{Type: "ntfy", URL: server.URL + "/paisa"})
+	assert.NoError(t, err)
+	assert.NoError(t, notifier.Notify(context.Background(), notification))
+	assert.Equal(t, notification.Title, title)
+	assert.Equal(t, "high", priority)
+	assert.Equal(t, notification.Message, body)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+
+	notifier, _ = Build(config.Notifier{Type: "ntfy", URL: failing.URL})
+	assert.Error(t, notifier.Notify(context.Background(), notification))
+}
+
+// smtpStub accepts a single mail and sends the DATA section on the channel
+func smtpStub(t *testing.T) (int, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	messages := make(chan string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 end with .")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				messages <- data.String()
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, messages
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	port, messages := smtpStub(t)
+
+	notifier, err := Build(config.Notifier{Type: "smtp", Host: "127.0.0.1", Port: port, From: "paisa@example.com", To: []string{"me@example.com"}})
+	assert.NoError(t, err)
+	assert.NoError(t, notifier.Notify(context.Background(), notification))
+
+	message := <-messages
+	assert.Contains(t, message, "Subject: Background task failed")
+	assert.Contains(t, message, "To: me@example.com")
+	assert.Contains(t, message, "network is down")
+}
+
+func TestDesktopNotifierDoesNotBlock(t *testing.T) {
+	timeout := desktopTimeout
+	t.Cleanup(func() { desktopTimeout = timeout })
+	desktopTimeout = 10 * time.Millisecond
+	dismissed := make(chan struct{})
+	defer close(dismissed)
+	defer SetDesktopHandler(nil)
+	SetDesktopHandler(func(notification Notification) error {
+		<-dismissed
+		return nil
+	})
+
+	notifier := &DesktopNotifier{}
+	assert.NoError(t, notifier.Notify(context.Background(), notification))
+}
+
+func TestBuildUnknown(t *testing.T) {
+	_, err := Build(config.Notifier{Type: "pager"})
+	assert.Error(t, err)
+}
+
+func TestSubscribed(t *testing.T) {
+	assert.True(t, subscribed(config.Notifier{}, EventBudget))
+	assert.True(t, subscribed(config.Notifier{Events: []string{EventBudget}}, EventBudget))
+	assert.False(t, subscribed(config.Notifier{Events: []string{EventTaskFailure}}, EventBudget))
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPNotifier sends the notification as a plain text email
+type SMTPNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification Notification) error {
+	if len(n.To) == 0 {
+		return fmt.Errorf("no recipients configured")
+	}
+
+	port := n.Port
+	if port == 0 {
+		port = 587
+	}
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	}
+
+	headers := []string{
+		"From: " + n.From,
+		"To: " + strings.Join(n.To, ", "),
+		"Subject: " + notification.Title,
+		"Date: " + notification.Time.Format("Mon, 02 Jan 2006 15:04:05 -0700"),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	message := strings.Join(headers, "\r\n") + "\r\n\r\n" + notification.Message + "\r\n"
+
+	return smtp.SendMail(fmt.Sprintf("%s:%d", n.Host, port), auth, n.From, n.To, []byte(message))
+}
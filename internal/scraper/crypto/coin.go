@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/crypto/coin"
+	"github.com/ananthakumaran/paisa/internal/model/price"
+)
+
+func GetCoins() ([]*coin.Coin, error) {
+	log.Info("Fetching the list of coins from CoinGecko")
+	respBytes, err := client.Get(baseURL + "/coins/list")
+	if err != nil {
+		return nil, err
+	}
+
+	type Data struct {
+		ID     string `json:"id"`
+		Symbol string `json:"symbol"`
+		Name   string `json:"name"`
+	}
+
+	var result []Data
+	err = json.Unmarshal(respBytes, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	var coins []*coin.Coin
+	for _, data := range result {
+		coins = append(coins, &coin.Coin{CoinID: data.ID, Symbol: data.Symbol, Name: data.Name})
+	}
+	return coins, nil
+}
+
+// GetHistory fetches the daily prices on or after the since date, the
+// whole history is fetched if since is zero
+func GetHistory(coinID string, commodityName string, since time.Time) ([]*price.Price, error) {
+	days := "max"
+	if !since.IsZero() {
+		days = fmt.Sprint(int(math.Ceil(time.Since(since).Hours()/24)) + 1)
+	}
+
+	log.Info("Fetching crypto price history from CoinGecko")
+	url := fmt.Sprintf("%s/coins/%s/market_chart?vs_currency=%s&days=%s&interval=daily", baseURL, coinID, strings.ToLower(config.DefaultCurrency()), days)
+	respBytes, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	type Result struct {
+		Prices [][2]decimal.Decimal `json:"prices"`
+	}
+
+	var result Result
+	err = json.Unmarshal(respBytes, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	// the last entry is the current price, which shares the date with
+	// the opening price of the day, the later one wins
+	byDate := make(map[time.Time]*price.Price)
+	var dates []time.Time
+	for _, data := range result.Prices {
+		timestamp := time.UnixMilli(data[0].IntPart()).In(config.TimeZone())
+		date := time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, config.TimeZone())
+		if date.Before(since) {
+			continue
+		}
+
+		if _, ok := byDate[date]; !ok {
+			dates = append(dates, date)
+		}
+		byDate[date] = &price.Price{Date: date, CommodityType: config.Crypto, CommodityID: coinID, CommodityName: commodityName, Value: data[1]}
+	}
+
+	prices := make([]*price.Price, 0, len(dates))
+	for _, date := range dates {
+		prices = append(prices, byDate[date])
+	}
+	return prices, nil
+}
@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/model/crypto/coin"
+	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/ananthakumaran/paisa/internal/scraper/fetch"
+)
+
+var baseURL = "https://api.coingecko.com/api/v3"
+
+// the public api allows around 5 to 15 requests per minute
+var client = fetch.New("CoinGecko", fetch.Options{Rate: 10.0 / 60, Burst: 3, Cache: true})
+
+type PriceProvider struct {
+}
+
+func (p *PriceProvider) Code() string {
+	return "com-coingecko"
+}
+
+func (p *PriceProvider) Label() string {
+	return "CoinGecko"
+}
+
+func (p *PriceProvider) Description() string {
+	return "Supports a large set of cryptocurrencies. The daily price is fetched in your default currency."
+}
+
+func (p *PriceProvider) AutoCompleteFields() []price.AutoCompleteField {
+	return []price.AutoCompleteField{
+		{Label: "Coin", ID: "coin", Help: "Type the name or the symbol of the coin, like Bitcoin or BTC"},
+	}
+}
+
+func (p *PriceProvider) AutoComplete(db *gorm.DB, field string, filter map[string]string) []price.AutoCompleteItem {
+	count := coin.Count(db)
+	if count == 0 {
+		coins, err := GetCoins()
+		if err != nil {
+			log.Error(err)
+			return []price.AutoCompleteItem{}
+		}
+		coin.UpsertAll(db, coins)
+	} else {
+		log.Info("Using cached results")
+	}
+
+	switch field {
+	case "coin":
+		return coin.GetCoinCompletions(db)
+	}
+	return []price.AutoCompleteItem{}
+}
+
+func (p *PriceProvider) ClearCache(db *gorm.DB) {
+	db.Exec("DELETE FROM crypto_coins")
+}
+
+func (p *PriceProvider) GetPrices(code string, commodityName string) ([]*price.Price, error) {
+	return GetHistory(code, commodityName, time.Time{})
+}
+
+func (p *PriceProvider) GetPricesSince(code string, commodityName string, since time.Time) ([]*price.Price, error) {
+	return GetHistory(code, commodityName, since)
+}
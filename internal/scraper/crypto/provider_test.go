@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+)
+
+func serveFixtures(t *testing.T) *[]string {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.String())
+		fixture := "testdata/market_chart.json"
+		if r.URL.Path == "/coins/list" {
+			fixture = "testdata/coins.json"
+		}
+		content, err := os.ReadFile(fixture)
+		assert.NoError(t, err)
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	original := baseURL
+	baseURL = server.URL
+	t.Cleanup(func() { baseURL = original })
+
+	assert.NoError(t, config.LoadConfig([]byte("journal_path: main.ledger\ndb_path: paisa.db\ntime_zone: UTC\n"), ""))
+	return &requested
+}
+
+func TestGetCoins(t *testing.T) {
+	serveFixtures(t)
+
+	coins, err := GetCoins()
+	assert.NoError(t, err)
+	assert.Len(t, coins, 2)
+	assert.Equal(t, "bitcoin", coins[0].CoinID)
+	assert.Equal(t, "btc", coins[0].Symbol)
+	assert.Equal(t, "Bitcoin", coins[0].Name)
+}
+
+func TestGetHistory(t *testing.T) {
+	requested := serveFixtures(t)
+
+	prices, err := (&PriceProvider{}).GetPrices("bitcoin", "BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, "/coins/bitcoin/market_chart?vs_currency=inr&days=max&interval=daily", (*requested)[0])
+
+	assert.Len(t, prices, 3)
+	assert.Equal(t, config.Crypto, prices[0].CommodityType)
+	assert.Equal(t, "bitcoin", prices[0].CommodityID)
+	assert.Equal(t, "BTC", prices[0].CommodityName)
+	assert.Equal(t, "2024-01-01", prices[0].Date.Format("2006-01-02"))
+	assert.Equal(t, 3537942.12, prices[0].Value.InexactFloat64())
+	assert.Equal(t, "2024-01-03", prices[2].Date.Format("2006-01-02"))
+	assert.Equal(t, 3560101.25, prices[2].Value.InexactFloat64())
+
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	prices, err = (&PriceProvider{}).GetPricesSince("bitcoin", "BTC", since)
+	assert.NoError(t, err)
+	assert.Contains(t, (*requested)[1], "&days=")
+	assert.NotContains(t, (*requested)[1], "days=max")
+	assert.Len(t, prices, 2)
+}
@@ -0,0 +1,107 @@
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// entry is a cached response along with the validators used for the
+// conditional requests
+type entry struct {
+	ETag         string `json:"etag"`
+	LastModified string `json:"last_modified"`
+	Body         []byte `json:"-"`
+}
+
+// cacheDir is a variable to allow the tests to use a temporary
+// directory
+var cacheDir = func() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "paisa", "http")
+}
+
+// entries are named by the hash of the url, which may contain api keys
+func entryPath(url string) string {
+	dir := cacheDir()
+	if dir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:]))
+}
+
+func loadEntry(url string) *entry {
+	path := entryPath(url)
+	if path == "" {
+		return nil
+	}
+
+	meta, err := os.ReadFile(path + ".json")
+	if err != nil {
+		return nil
+	}
+
+	var e entry
+	if json.Unmarshal(meta, &e) != nil {
+		return nil
+	}
+
+	e.Body, err = os.ReadFile(path + ".body")
+	if err != nil {
+		return nil
+	}
+
+	return &e
+}
+
+func (e *entry) addValidators(req *http.Request) {
+	if e == nil {
+		return
+	}
+
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// storeEntry caches the response only if it has validators, there is
+// no point in caching responses which can't be revalidated
+func storeEntry(url string, header http.Header, body []byte) {
+	e := entry{ETag: header.Get("ETag"), LastModified: header.Get("Last-Modified")}
+	if e.ETag == "" && e.LastModified == "" {
+		return
+	}
+
+	path := entryPath(url)
+	if path == "" {
+		return
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err == nil {
+		err = os.WriteFile(path+".body", body, 0600)
+	}
+	if err == nil {
+		var meta []byte
+		meta, err = json.Marshal(e)
+		if err == nil {
+			err = os.WriteFile(path+".json", meta, 0600)
+		}
+	}
+
+	if err != nil {
+		log.Warnf("Failed to cache the response: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Options configures the client of a price provider, the zero value
+// uses the defaults.
+type Options struct {
+	// Timeout of a single attempt, including reading the body
+	Timeout time.Duration
+	// Retries is the number of attempts after the first one failed
+	Retries int
+	// Backoff is the wait before the first retry, doubled on every
+	// retry
+	Backoff time.Duration
+	// Rate is the sustained number of requests per second, zero
+	// means no limit
+	Rate float64
+	// Burst is the number of requests that can be made at once
+	Burst int
+	// Cache stores the responses with validators and sends
+	// conditional requests
+	Cache bool
+}
+
+const (
+	defaultTimeout = 30 * time.Second
+	defaultRetries = 3
+	defaultBackoff = 1 * time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+// Client is a http client with timeout, retry, rate limit and
+// conditional request caching
+type Client struct {
+	name    string
+	options Options
+	http    *http.Client
+	limiter *tokenBucket
+}
+
+func New(name string, options Options) *Client {
+	if options.Timeout == 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.Retries == 0 {
+		options.Retries = defaultRetries
+	}
+	if options.Backoff == 0 {
+		options.Backoff = defaultBackoff
+	}
+
+	client := &Client{name: name, options: options, http: &http.Client{Timeout: options.Timeout}}
+	if options.Rate > 0 {
+		client.limiter = newTokenBucket(options.Rate, options.Burst)
+	}
+	return client
+}
+
+// StatusError is returned when the server responds with a non 2xx
+// status after all the retries
+type StatusError struct {
+	Host       string
+	StatusCode int
+	Body       string
+}
+
+// the url is not included as some providers take the api key as a
+// query parameter
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Unexpected status code: %d from %s, body: %s", e.StatusCode, e.Host, e.Body)
+}
+
+func (c *Client) Get(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends the request and returns the body of the response. The
+// request is retried on network errors, 429 and 5xx responses.
+func (c *Client) Do(req *http.Request) ([]byte, error) {
+	var cached *entry
+	if c.options.Cache && req.Method == "GET" {
+		cached = loadEntry(req.URL.String())
+		cached.addValidators(req)
+	}
+
+	backoff := c.options.Backoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var body []byte
+		var retryAfter time.Duration
+		body, retryAfter, err = c.attempt(req, cached)
+		if err == nil {
+			return body, nil
+		}
+
+		if retryAfter < 0 || attempt >= c.options.Retries {
+			return nil, err
+		}
+
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		log.Warnf("%s request failed, retrying in %v: %v", c.name, wait.Round(time.Millisecond), err)
+
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+
+		if req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// attempt returns a negative retryAfter if the error is not worth
+// retrying
+func (c *Client) attempt(req *http.Request, cached *entry) ([]byte, time.Duration, error) {
+	if c.limiter != nil {
+		err := c.limiter.wait(req.Context())
+		if err != nil {
+			return nil, -1, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), c.options.Timeout)
+	defer cancel()
+
+	resp, err := c.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil && cached.Body != nil {
+		log.Debugf("%s response for %s not modified", c.name, req.URL)
+		return cached.Body, 0, nil
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if c.options.Cache && req.Method == "GET" {
+			storeEntry(req.URL.String(), resp.Header, body)
+		}
+		return body, 0, nil
+	}
+
+	err = &StatusError{Host: req.URL.Host, StatusCode: resp.StatusCode, Body: truncate(string(body), 200)}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), err
+	}
+	return nil, -1, err
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return min(time.Duration(seconds)*time.Second, maxBackoff)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(min(time.Until(date), maxBackoff), 0)
+	}
+
+	return 0
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,96 @@
+package fetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func useTempCache(t *testing.T) {
+	dir := t.TempDir()
+	original := cacheDir
+	cacheDir = func() string { return dir }
+	t.Cleanup(func() { cacheDir = original })
+}
+
+func TestRetry(t *testing.T) {
+	useTempCache(t)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := New("test", Options{Backoff: time.Millisecond})
+	body, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, 3, attempts)
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	useTempCache(t)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := New("test", Options{Backoff: time.Millisecond})
+	_, err := client.Get(server.URL + "?apikey=secret")
+	assert.Equal(t, 1, attempts)
+
+	var statusError *StatusError
+	assert.ErrorAs(t, err, &statusError)
+	assert.Equal(t, http.StatusNotFound, statusError.StatusCode)
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestConditionalCache(t *testing.T) {
+	useTempCache(t)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("prices"))
+	}))
+	defer server.Close()
+
+	client := New("test", Options{Cache: true})
+	body, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "prices", string(body))
+
+	body, err = client.Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "prices", string(body))
+	assert.Equal(t, 2, attempts)
+}
+
+func TestTokenBucket(t *testing.T) {
+	bucket := newTokenBucket(2, 2)
+	now := bucket.last
+	assert.Equal(t, time.Duration(0), bucket.reserve(now))
+	assert.Equal(t, time.Duration(0), bucket.reserve(now))
+	assert.Equal(t, 500*time.Millisecond, bucket.reserve(now))
+	assert.Equal(t, 500*time.Millisecond, bucket.reserve(now.Add(500*time.Millisecond)))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5"))
+	assert.Equal(t, maxBackoff, parseRetryAfter("3600"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+}
@@ -0,0 +1,77 @@
+package fx
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/scraper/fetch"
+)
+
+// the base url is a variable to allow the tests to use a local server
+var frankfurterBaseURL = "https://api.frankfurter.dev/v1"
+
+var frankfurterClient = fetch.New("Frankfurter", fetch.Options{Rate: 2, Burst: 2, Cache: true})
+
+// the reference rates published by the European Central Bank start
+// from this date
+const frankfurterStartDate = "1999-01-04"
+
+type frankfurterResponse struct {
+	Base  string                                `json:"base"`
+	Rates map[string]map[string]decimal.Decimal `json:"rates"`
+}
+
+// Frankfurter fetches the reference rates published by the European
+// Central Bank, the rates are available only on the working days.
+type Frankfurter struct {
+}
+
+func (f *Frankfurter) Name() string {
+	return "frankfurter"
+}
+
+func (f *Frankfurter) GetRates(from string, to string, since time.Time) ([]Rate, error) {
+	start := frankfurterStartDate
+	if !since.IsZero() {
+		start = since.Format("2006-01-02")
+	}
+
+	log.Infof("Fetching exchange rates of %s to %s from Frankfurter", from, to)
+	url := fmt.Sprintf("%s/%s..?base=%s&symbols=%s", frankfurterBaseURL, start, from, to)
+	body, err := frankfurterClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var response frankfurterResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []Rate
+	for date, values := range response.Rates {
+		value, ok := values[to]
+		if !ok {
+			continue
+		}
+
+		dateTime, err := time.ParseInLocation("2006-01-02", date, config.TimeZone())
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, Rate{Date: dateTime, Value: value})
+	}
+
+	if len(rates) == 0 && since.IsZero() {
+		return nil, fmt.Errorf("No exchange rates found for %s to %s", from, to)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,75 @@
+package fx
+
+import (
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"golang.org/x/text/currency"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/price"
+)
+
+// IsCurrencyCode checks whether the commodity is an ISO 4217 currency
+// code like USD
+func IsCurrencyCode(commodity string) bool {
+	if len(commodity) != 3 || commodity != strings.ToUpper(commodity) {
+		return false
+	}
+	_, err := currency.ParseISO(commodity)
+	return err == nil
+}
+
+func GetPricesSince(code string, commodityName string, since time.Time) ([]*price.Price, error) {
+	source, err := GetSource(config.GetConfig().FX.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := source.GetRates(code, config.DefaultCurrency(), since)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(rates, func(rate Rate, _ int) *price.Price {
+		return &price.Price{Date: rate.Date, CommodityType: config.Currency, CommodityID: code, CommodityName: commodityName, Value: rate.Value}
+	}), nil
+}
+
+type PriceProvider struct {
+}
+
+func (p *PriceProvider) Code() string {
+	return "fx"
+}
+
+func (p *PriceProvider) Label() string {
+	return "Exchange Rate"
+}
+
+func (p *PriceProvider) Description() string {
+	return "Daily exchange rates of the currency to your default currency. The rates are fetched from the source configured under fx, Frankfurter by default, which publishes the European Central Bank reference rates."
+}
+
+func (p *PriceProvider) AutoCompleteFields() []price.AutoCompleteField {
+	return []price.AutoCompleteField{
+		{Label: "Currency", ID: "currency", Help: "ISO 4217 currency code like USD or EUR", InputType: "text"},
+	}
+}
+
+func (p *PriceProvider) AutoComplete(db *gorm.DB, field string, filter map[string]string) []price.AutoCompleteItem {
+	return []price.AutoCompleteItem{}
+}
+
+func (p *PriceProvider) ClearCache(db *gorm.DB) {
+}
+
+func (p *PriceProvider) GetPrices(code string, commodityName string) ([]*price.Price, error) {
+	return GetPricesSince(code, commodityName, time.Time{})
+}
+
+func (p *PriceProvider) GetPricesSince(code string, commodityName string, since time.Time) ([]*price.Price, error) {
+	return GetPricesSince(code, commodityName, since)
+}
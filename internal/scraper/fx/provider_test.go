@@ -0,0 +1,52 @@
+package fx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+)
+
+func TestIsCurrencyCode(t *testing.T) {
+	assert.True(t, IsCurrencyCode("USD"))
+	assert.True(t, IsCurrencyCode("EUR"))
+	assert.False(t, IsCurrencyCode("usd"))
+	assert.False(t, IsCurrencyCode("NIFTY"))
+	assert.False(t, IsCurrencyCode("ABC"))
+}
+
+func TestGetPricesSince(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/frankfurter.json")
+	assert.NoError(t, err)
+
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.String()
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	original := frankfurterBaseURL
+	frankfurterBaseURL = server.URL
+	defer func() { frankfurterBaseURL = original }()
+
+	assert.NoError(t, config.LoadConfig([]byte("journal_path: main.ledger\ndb_path: paisa.db\n"), ""))
+
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	prices, err := (&PriceProvider{}).GetPricesSince("USD", "USD", since)
+	assert.NoError(t, err)
+	assert.Equal(t, "/2024-01-02..?base=USD&symbols=INR", requested)
+
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Date.Before(prices[j].Date) })
+	assert.Len(t, prices, 4)
+	assert.Equal(t, config.Currency, prices[0].CommodityType)
+	assert.Equal(t, "2024-01-02", prices[0].Date.Format("2006-01-02"))
+	assert.Equal(t, 83.25, prices[0].Value.InexactFloat64())
+	assert.Equal(t, 83.14, prices[3].Value.InexactFloat64())
+}
@@ -0,0 +1,33 @@
+package fx
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Rate struct {
+	Date  time.Time
+	Value decimal.Decimal
+}
+
+// Source provides the daily exchange rates between two currencies
+type Source interface {
+	Name() string
+	// GetRates returns the value of one unit of from in to, starting at
+	// the since date. The whole history is returned if since is zero.
+	GetRates(from string, to string, since time.Time) ([]Rate, error)
+}
+
+var sources = map[string]Source{
+	"frankfurter": &Frankfurter{},
+}
+
+func GetSource(name string) (Source, error) {
+	source, ok := sources[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown exchange rate source: %s", name)
+	}
+	return source, nil
+}
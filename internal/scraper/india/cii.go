@@ -1,24 +1,18 @@
 package india
 
 import (
-	"io"
-	"net/http"
-
 	"encoding/json"
 
 	"github.com/ananthakumaran/paisa/internal/model/cii"
+	"github.com/ananthakumaran/paisa/internal/scraper/fetch"
 	log "github.com/sirupsen/logrus"
 )
 
+var client = fetch.New("Purified Bytes India", fetch.Options{Cache: true})
+
 func GetCostInflationIndex() ([]*cii.CII, error) {
 	log.Info("Fetching Cost Inflation Index from Purified Bytes")
-	resp, err := http.Get("https://india.purifiedbytes.com/api/cii/v2.json")
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	respBytes, err := io.ReadAll(resp.Body)
+	respBytes, err := client.Get("https://india.purifiedbytes.com/api/cii/v2.json")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,264 @@
+package local
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/commodity"
+	"github.com/ananthakumaran/paisa/internal/model/price"
+)
+
+// date formats tried in order when the format is not specified
+var dateFormats = []string{"2006-01-02", "2006/01/02", "02-01-2006", "02/01/2006", "02 Jan 2006", "Jan 02, 2006"}
+
+// Mapping describes where to find the date and price in a price file
+type Mapping struct {
+	Date       string
+	Price      string
+	DateFormat string
+	Delimiter  rune
+}
+
+// ParseCode splits the code into the path pattern and the column
+// mapping. The mapping is specified after # as key=value pairs
+// separated by &, e.g.
+// prices/esop.csv#date=Date&price=Close&date_format=02/01/2006
+func ParseCode(code string) (string, Mapping, error) {
+	mapping := Mapping{Date: "date", Price: "price", Delimiter: ','}
+	pattern, query, found := strings.Cut(code, "#")
+	if !found {
+		return pattern, mapping, nil
+	}
+
+	for _, option := range strings.Split(query, "&") {
+		key, value, found := strings.Cut(option, "=")
+		if !found {
+			return "", mapping, fmt.Errorf("Invalid column mapping option %s, expected key=value", option)
+		}
+
+		switch key {
+		case "date":
+			mapping.Date = value
+		case "price":
+			mapping.Price = value
+		case "date_format":
+			mapping.DateFormat = value
+		case "delimiter":
+			if len([]rune(value)) != 1 {
+				return "", mapping, fmt.Errorf("Delimiter should be a single character, got %q", value)
+			}
+			mapping.Delimiter = []rune(value)[0]
+		default:
+			return "", mapping, fmt.Errorf("Unknown column mapping option %s", key)
+		}
+	}
+
+	return pattern, mapping, nil
+}
+
+// GetPrices reads the prices from all the files matching the code, the
+// relative paths are resolved against the journal directory
+func GetPrices(code string, commodityName string) ([]*price.Price, error) {
+	pattern, mapping, err := ParseCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(config.GetJournalPath()), pattern)
+	}
+
+	paths, err := doublestar.FilepathGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("No price file found matching %s", pattern)
+	}
+
+	commodityType := commodity.FindByName(commodityName).Type
+	if commodityType == "" {
+		commodityType = config.Unknown
+	}
+
+	// later files take precedence if there are multiple prices for
+	// the same date
+	byDate := make(map[time.Time]*price.Price)
+	for _, path := range paths {
+		log.Info("Reading prices from ", path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var rows []row
+		if strings.ToLower(filepath.Ext(path)) == ".json" {
+			rows, err = parseJSON(content, mapping)
+		} else {
+			rows, err = parseCSV(content, mapping)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse %s: %w", path, err)
+		}
+
+		for _, r := range rows {
+			date, value, err := r.parse(mapping)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to parse %s: %w", path, err)
+			}
+
+			byDate[date] = &price.Price{Date: date, CommodityType: commodityType, CommodityID: code, CommodityName: commodityName, Value: value}
+		}
+	}
+
+	prices := make([]*price.Price, 0, len(byDate))
+	for _, p := range byDate {
+		prices = append(prices, p)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Date.Before(prices[j].Date) })
+	return prices, nil
+}
+
+type row struct {
+	line  int
+	date  string
+	price string
+}
+
+func (r row) parse(mapping Mapping) (time.Time, decimal.Decimal, error) {
+	date, err := parseDate(strings.TrimSpace(r.date), mapping.DateFormat)
+	if err != nil {
+		return time.Time{}, decimal.Zero, fmt.Errorf("row %d: %w", r.line, err)
+	}
+
+	value, err := decimal.NewFromString(strings.ReplaceAll(strings.TrimSpace(r.price), ",", ""))
+	if err != nil {
+		return time.Time{}, decimal.Zero, fmt.Errorf("row %d: invalid price %q", r.line, r.price)
+	}
+
+	return date, value, nil
+}
+
+func parseDate(value string, format string) (time.Time, error) {
+	if format != "" {
+		return time.ParseInLocation(format, value, config.TimeZone())
+	}
+
+	for _, format := range dateFormats {
+		date, err := time.ParseInLocation(format, value, config.TimeZone())
+		if err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, specify the date_format", value)
+}
+
+func parseCSV(content []byte, mapping Mapping) ([]row, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = mapping.Delimiter
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	dateIndex, priceIndex := -1, -1
+	for i, column := range records[0] {
+		column = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+		if strings.EqualFold(column, mapping.Date) {
+			dateIndex = i
+		}
+		if strings.EqualFold(column, mapping.Price) {
+			priceIndex = i
+		}
+	}
+
+	if dateIndex == -1 || priceIndex == -1 {
+		return nil, fmt.Errorf("columns %q and %q not found in the header %v", mapping.Date, mapping.Price, records[0])
+	}
+
+	var rows []row
+	for i, record := range records[1:] {
+		if len(record) <= max(dateIndex, priceIndex) || (len(record) == 1 && record[0] == "") {
+			continue
+		}
+		rows = append(rows, row{line: i + 2, date: record[dateIndex], price: record[priceIndex]})
+	}
+	return rows, nil
+}
+
+func parseJSON(content []byte, mapping Mapping) ([]row, error) {
+	var records []map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	err := decoder.Decode(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []row
+	for i, record := range records {
+		date, ok := record[mapping.Date]
+		if !ok {
+			return nil, fmt.Errorf("row %d: key %q not found", i+1, mapping.Date)
+		}
+		value, ok := record[mapping.Price]
+		if !ok {
+			return nil, fmt.Errorf("row %d: key %q not found", i+1, mapping.Price)
+		}
+		rows = append(rows, row{line: i + 1, date: fmt.Sprint(date), price: fmt.Sprint(value)})
+	}
+	return rows, nil
+}
+
+type PriceProvider struct {
+}
+
+func (p *PriceProvider) Code() string {
+	return "local-file"
+}
+
+func (p *PriceProvider) Label() string {
+	return "Local File"
+}
+
+func (p *PriceProvider) Description() string {
+	return "Reads the prices from CSV or JSON files, useful for holdings like unlisted shares or foreign brokers which have no online source. The file should have a date and a price column."
+}
+
+func (p *PriceProvider) AutoCompleteFields() []price.AutoCompleteField {
+	return []price.AutoCompleteField{
+		{Label: "File", ID: "file", Help: "Path or glob pattern of the price files, relative to the journal directory. The column names default to date and price, use <code>prices/esop.csv#date=Date&price=Close&date_format=02/01/2006</code> to change them.", InputType: "text"},
+	}
+}
+
+func (p *PriceProvider) AutoComplete(db *gorm.DB, field string, filter map[string]string) []price.AutoCompleteItem {
+	return []price.AutoCompleteItem{}
+}
+
+func (p *PriceProvider) ClearCache(db *gorm.DB) {
+}
+
+func (p *PriceProvider) GetPrices(code string, commodityName string) ([]*price.Price, error) {
+	return GetPrices(code, commodityName)
+}
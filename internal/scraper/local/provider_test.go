@@ -0,0 +1,41 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCode(t *testing.T) {
+	pattern, mapping, err := ParseCode("prices/esop.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "prices/esop.csv", pattern)
+	assert.Equal(t, Mapping{Date: "date", Price: "price", Delimiter: ','}, mapping)
+
+	pattern, mapping, err = ParseCode("prices/*.csv#date=Trade Date&price=Close&date_format=02/01/2006&delimiter=;")
+	assert.NoError(t, err)
+	assert.Equal(t, "prices/*.csv", pattern)
+	assert.Equal(t, Mapping{Date: "Trade Date", Price: "Close", DateFormat: "02/01/2006", Delimiter: ';'}, mapping)
+
+	_, _, err = ParseCode("prices/esop.csv#column=Close")
+	assert.Error(t, err)
+}
+
+func TestGetPrices(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "esop-2022.csv"), []byte("Date;Open;Close\n31/12/2022;10;\"1,100.5\"\n01/01/2023;11;1200\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "esop-2023.json"), []byte(`[{"Date": "01/01/2023", "Close": 1250}, {"Date": "02/01/2023", "Close": "1300"}]`), 0644))
+
+	prices, err := GetPrices(filepath.Join(dir, "esop-*")+"#date=Date&price=Close&date_format=02/01/2006&delimiter=;", "ESOP")
+	assert.NoError(t, err)
+	assert.Len(t, prices, 3)
+	assert.Equal(t, "2022-12-31", prices[0].Date.Format("2006-01-02"))
+	assert.Equal(t, 1100.5, prices[0].Value.InexactFloat64())
+	assert.Equal(t, 1250.0, prices[1].Value.InexactFloat64())
+	assert.Equal(t, 1300.0, prices[2].Value.InexactFloat64())
+
+	_, err = GetPrices(filepath.Join(dir, "missing.csv"), "ESOP")
+	assert.Error(t, err)
+}
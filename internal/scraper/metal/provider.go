@@ -4,16 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"gorm.io/gorm"
-	"io"
-	"net/http"
 	"time"
 
 	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/ananthakumaran/paisa/internal/scraper/fetch"
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 )
 
+var client = fetch.New("Purified Bytes Metal", fetch.Options{Rate: 2, Burst: 4, Cache: true})
+
 type PriceProvider struct {
 }
 
@@ -52,13 +53,7 @@ func (p *PriceProvider) ClearCache(db *gorm.DB) {
 func (p *PriceProvider) GetPrices(code string, commodityName string) ([]*price.Price, error) {
 	log.Info("Fetching Metal price history from Purified Bytes")
 	url := fmt.Sprintf("https://india.purifiedbytes.com/api/metal/%s/price.json", code)
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	respBytes, err := io.ReadAll(resp.Body)
+	respBytes, err := client.Get(url)
 	if err != nil {
 		return nil, err
 	}
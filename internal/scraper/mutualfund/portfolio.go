@@ -3,7 +3,6 @@ package mutualfund
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 
@@ -34,16 +33,13 @@ WHERE s.code = %s
 	query := fmt.Sprintf(q, schemeCode)
 
 	req, err := http.NewRequest("POST", url, strings.NewReader(query))
-	req.Header.Add("Content-Type", "text/plain")
-	req.Header.Add("Authorization", "Basic cGxheTo=")
-	resp, err := http.DefaultClient.Do(req)
-
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	req.Header.Add("Content-Type", "text/plain")
+	req.Header.Add("Authorization", "Basic cGxheTo=")
 
-	respBytes, err := io.ReadAll(resp.Body)
+	respBytes, err := portfolioClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -1,12 +1,21 @@
 package mutualfund
 
 import (
+	"time"
+
 	"github.com/ananthakumaran/paisa/internal/model/mutualfund/scheme"
 	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/ananthakumaran/paisa/internal/scraper/fetch"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+var (
+	mfapiClient     = fetch.New("MF API", fetch.Options{Rate: 5, Burst: 5, Cache: true})
+	amfiClient      = fetch.New("AMFI", fetch.Options{Timeout: 2 * time.Minute, Cache: true})
+	portfolioClient = fetch.New("Purified Bytes Mutual Fund", fetch.Options{Rate: 2, Burst: 4})
+)
+
 type PriceProvider struct {
 }
 
@@ -1,8 +1,8 @@
 package mutualfund
 
 import (
+	"bytes"
 	"encoding/csv"
-	"net/http"
 
 	"github.com/ananthakumaran/paisa/internal/model/mutualfund/scheme"
 	log "github.com/sirupsen/logrus"
@@ -10,13 +10,12 @@ import (
 
 func GetSchemes() ([]*scheme.Scheme, error) {
 	log.Info("Fetching Mutual Fund Scheme list from AMFI Website")
-	resp, err := http.Get("https://portal.amfiindia.com/DownloadSchemeData_Po.aspx?mf=0")
+	respBytes, err := amfiClient.Get("https://portal.amfiindia.com/DownloadSchemeData_Po.aspx?mf=0")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	reader := csv.NewReader(resp.Body)
+	reader := csv.NewReader(bytes.NewReader(respBytes))
 	reader.LazyQuotes = true
 	records, err := reader.ReadAll()
 	if err != nil {
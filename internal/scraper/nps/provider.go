@@ -3,10 +3,13 @@ package nps
 import (
 	"github.com/ananthakumaran/paisa/internal/model/nps/scheme"
 	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/ananthakumaran/paisa/internal/scraper/fetch"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+var client = fetch.New("Purified Bytes NPS", fetch.Options{Rate: 2, Burst: 4, Cache: true})
+
 type PriceProvider struct {
 }
 
@@ -1,9 +1,6 @@
 package nps
 
 import (
-	"io"
-	"net/http"
-
 	"encoding/json"
 
 	"github.com/ananthakumaran/paisa/internal/model/nps/scheme"
@@ -12,13 +9,7 @@ import (
 
 func GetSchemes() ([]*scheme.Scheme, error) {
 	log.Info("Fetching NPS scheme list from Purified Bytes")
-	resp, err := http.Get("https://nps.purifiedbytes.com/api/schemes.json")
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	respBytes, err := io.ReadAll(resp.Body)
+	respBytes, err := client.Get("https://nps.purifiedbytes.com/api/schemes.json")
 	if err != nil {
 		return nil, err
 	}
@@ -1,12 +1,19 @@
 package scraper
 
 import (
+	"fmt"
+
+	"github.com/samber/lo"
+
+	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/ananthakumaran/paisa/internal/scraper/crypto"
+	"github.com/ananthakumaran/paisa/internal/scraper/fx"
+	"github.com/ananthakumaran/paisa/internal/scraper/local"
 	"github.com/ananthakumaran/paisa/internal/scraper/metal"
 	"github.com/ananthakumaran/paisa/internal/scraper/mutualfund"
 	"github.com/ananthakumaran/paisa/internal/scraper/nps"
 	"github.com/ananthakumaran/paisa/internal/scraper/stock"
-	log "github.com/sirupsen/logrus"
 )
 
 func GetAllProviders() []price.PriceProvider {
@@ -16,23 +23,35 @@ func GetAllProviders() []price.PriceProvider {
 		&stock.AlphaVantagePriceProvider{},
 		&nps.PriceProvider{},
 		&metal.PriceProvider{},
+		&local.PriceProvider{},
+		&fx.PriceProvider{},
+		&crypto.PriceProvider{},
 	}
 
 }
 
-func GetProviderByCode(code string) price.PriceProvider {
+func init() {
+	config.RegisterPriceProviders(lo.Map(GetAllProviders(), func(provider price.PriceProvider, _ int) string { return provider.Code() }))
+}
+
+func GetProviderByCode(code string) (price.PriceProvider, error) {
 	switch code {
 	case "in-mfapi":
-		return &mutualfund.PriceProvider{}
+		return &mutualfund.PriceProvider{}, nil
 	case "com-purifiedbytes-nps":
-		return &nps.PriceProvider{}
+		return &nps.PriceProvider{}, nil
 	case "com-purifiedbytes-metal":
-		return &metal.PriceProvider{}
+		return &metal.PriceProvider{}, nil
 	case "com-yahoo":
-		return &stock.YahooPriceProvider{}
+		return &stock.YahooPriceProvider{}, nil
 	case "co-alphavantage":
-		return &stock.AlphaVantagePriceProvider{}
+		return &stock.AlphaVantagePriceProvider{}, nil
+	case "local-file":
+		return &local.PriceProvider{}, nil
+	case "fx":
+		return &fx.PriceProvider{}, nil
+	case "com-coingecko":
+		return &crypto.PriceProvider{}, nil
 	}
-	log.Fatal("Unknown price provider: ", code)
-	return nil
+	return nil, fmt.Errorf("Unknown price provider: %s", code)
 }
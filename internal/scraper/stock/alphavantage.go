@@ -3,13 +3,12 @@ package stock
 import (
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/ananthakumaran/paisa/internal/scraper/fetch"
 	"github.com/ananthakumaran/paisa/internal/utils"
 	"github.com/google/btree"
 	"github.com/samber/lo"
@@ -55,21 +54,15 @@ func (p AlphaVantageExchangePrice) Less(o btree.Item) bool {
 	return p.Date.Before(o.(AlphaVantageExchangePrice).Date)
 }
 
-func fetch[R any](url string, response *R) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
+// the free tier allows 5 requests per minute
+var alphaVantageClient = fetch.New("Alpha Vantage", fetch.Options{Rate: 5.0 / 60, Burst: 1, Cache: true})
 
-	respBytes, err := io.ReadAll(resp.Body)
+func fetchJSON[R any](url string, response *R) error {
+	respBytes, err := alphaVantageClient.Get(url)
 	if err != nil {
 		return err
 	}
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("Unexpected status code: %d, body: %s", resp.StatusCode, string(respBytes))
-	}
-
 	var errorResponse ErrorResponse
 	err = json.Unmarshal(respBytes, &errorResponse)
 	if err != nil {
@@ -88,17 +81,28 @@ func fetch[R any](url string, response *R) error {
 	return nil
 }
 
-func getHistory(code, commodityName string) ([]*price.Price, error) {
+// compact output has the latest 100 data points, which covers a little
+// over 3 months of trading days
+const compactOutputDays = 90
+
+// getHistory fetches the prices on or after the since date, the whole
+// history is fetched if since is zero
+func getHistory(code, commodityName string, since time.Time) ([]*price.Price, error) {
 	parts := strings.Split(code, ":")
 	if len(parts) != 3 {
 		return nil, fmt.Errorf("Invalid code: %s", code)
 	}
 	apiKey, ticker, currency := parts[0], parts[1], parts[2]
 
+	outputSize := "full"
+	if !since.IsZero() && time.Since(since) < compactOutputDays*24*time.Hour {
+		outputSize = "compact"
+	}
+
 	log.Info("Fetching stock price history from Alpha Vantage")
-	url := fmt.Sprintf("https://www.alphavantage.co/query?function=TIME_SERIES_DAILY&symbol=%s&outputsize=full&apikey=%s", ticker, apiKey)
+	url := fmt.Sprintf("https://www.alphavantage.co/query?function=TIME_SERIES_DAILY&symbol=%s&outputsize=%s&apikey=%s", ticker, outputSize, apiKey)
 	var response TimeSeriesDailyReponse
-	err := fetch(url, &response)
+	err := fetchJSON(url, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -108,9 +112,9 @@ func getHistory(code, commodityName string) ([]*price.Price, error) {
 	if !utils.IsCurrency(currency) {
 		needExchangePrice = true
 		log.Info("Fetching exchange rate from Alpha Vantage")
-		url = fmt.Sprintf("https://www.alphavantage.co/query?function=FX_DAILY&from_symbol=%s&to_symbol=%s&outputsize=full&apikey=%s", currency, config.DefaultCurrency(), apiKey)
+		url = fmt.Sprintf("https://www.alphavantage.co/query?function=FX_DAILY&from_symbol=%s&to_symbol=%s&outputsize=%s&apikey=%s", currency, config.DefaultCurrency(), outputSize, apiKey)
 		var response FXSeriesDailyReponse
-		err = fetch(url, &response)
+		err = fetchJSON(url, &response)
 		if err != nil {
 			return nil, err
 		}
@@ -136,6 +140,10 @@ func getHistory(code, commodityName string) ([]*price.Price, error) {
 		if err != nil {
 			return nil, err
 		}
+		if dateTime.Before(since) {
+			continue
+		}
+
 		value, err := decimal.NewFromString(value.Close)
 		if err != nil {
 			return nil, err
@@ -165,7 +173,7 @@ func getHistory(code, commodityName string) ([]*price.Price, error) {
 func searchTicker(apiKey, ticker string) (*SearchResponse, error) {
 	url := fmt.Sprintf("https://www.alphavantage.co/query?function=SYMBOL_SEARCH&keywords=%s&apikey=%s", ticker, apiKey)
 	var response SearchResponse
-	err := fetch(url, &response)
+	err := fetchJSON(url, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -221,5 +229,9 @@ func (p *AlphaVantagePriceProvider) ClearCache(db *gorm.DB) {
 }
 
 func (p *AlphaVantagePriceProvider) GetPrices(code string, commodityName string) ([]*price.Price, error) {
-	return getHistory(code, commodityName)
+	return getHistory(code, commodityName, time.Time{})
+}
+
+func (p *AlphaVantagePriceProvider) GetPricesSince(code string, commodityName string, since time.Time) ([]*price.Price, error) {
+	return getHistory(code, commodityName, since)
 }
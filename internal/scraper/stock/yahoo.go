@@ -3,7 +3,6 @@ package stock
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"math/rand"
 	"net/http"
 	"sync"
@@ -17,6 +16,7 @@ import (
 
 	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/ananthakumaran/paisa/internal/scraper/fetch"
 	"github.com/ananthakumaran/paisa/internal/utils"
 )
 
@@ -45,6 +45,8 @@ type UserAgent struct {
 
 var agent UserAgent
 
+var yahooClient = fetch.New("Yahoo", fetch.Options{Rate: 2, Burst: 2, Cache: true})
+
 func selectAgent() {
 	agent.name = UserAgents[rand.Intn(len(UserAgents))]
 }
@@ -84,8 +86,14 @@ func (p ExchangePrice) Less(o btree.Item) bool {
 }
 
 func GetHistory(ticker string, commodityName string) ([]*price.Price, error) {
+	return GetHistorySince(ticker, commodityName, time.Time{})
+}
+
+// GetHistorySince fetches the prices on or after the since date, the
+// whole history is fetched if since is zero
+func GetHistorySince(ticker string, commodityName string, since time.Time) ([]*price.Price, error) {
 	log.Info("Fetching stock price history from Yahoo")
-	response, err := getTicker(ticker)
+	response, err := getTicker(ticker, since)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +110,13 @@ func GetHistory(ticker string, commodityName string) ([]*price.Price, error) {
 
 	if !utils.IsCurrency(result.Meta.Currency) {
 		needExchangePrice = true
-		exchangeResponse, err := getTicker(fmt.Sprintf("%s%s=X", result.Meta.Currency, config.DefaultCurrency()))
+		// start a week earlier, the exchange might be closed on the
+		// since date
+		exchangeSince := since
+		if !since.IsZero() {
+			exchangeSince = since.AddDate(0, 0, -7)
+		}
+		exchangeResponse, err := getTicker(fmt.Sprintf("%s%s=X", result.Meta.Currency, config.DefaultCurrency()), exchangeSince)
 		if err != nil {
 			return nil, err
 		}
@@ -136,8 +150,14 @@ func GetHistory(ticker string, commodityName string) ([]*price.Price, error) {
 	return prices, nil
 }
 
-func getTicker(ticker string) (*Response, error) {
+func getTicker(ticker string, since time.Time) (*Response, error) {
 	url := fmt.Sprintf("https://query2.finance.yahoo.com/v8/finance/chart/%s?interval=1d&range=50y", ticker)
+	if !since.IsZero() {
+		// period2 is kept the same during the day, the cached response
+		// is keyed by the url
+		until := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+		url = fmt.Sprintf("https://query2.finance.yahoo.com/v8/finance/chart/%s?interval=1d&period1=%d&period2=%d", ticker, since.Unix(), until.Unix())
+	}
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -146,13 +166,7 @@ func getTicker(ticker string) (*Response, error) {
 	agent.Do(func() { selectAgent() })
 	req.Header.Add("User-Agent", agent.name)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	respBytes, err := io.ReadAll(resp.Body)
+	respBytes, err := yahooClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -197,3 +211,7 @@ func (p *YahooPriceProvider) ClearCache(db *gorm.DB) {
 func (p *YahooPriceProvider) GetPrices(code string, commodityName string) ([]*price.Price, error) {
 	return GetHistory(code, commodityName)
 }
+
+func (p *YahooPriceProvider) GetPricesSince(code string, commodityName string, since time.Time) ([]*price.Price, error) {
+	return GetHistorySince(code, commodityName, since)
+}
@@ -0,0 +1,61 @@
+package worldbank
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ananthakumaran/paisa/internal/model/cpi"
+	"github.com/ananthakumaran/paisa/internal/scraper/fetch"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+var baseURL = "https://api.worldbank.org/v2"
+
+var client = fetch.New("World Bank", fetch.Options{Cache: true})
+
+type indicatorValue struct {
+	Date  string           `json:"date"`
+	Value *decimal.Decimal `json:"value"`
+}
+
+// GetConsumerPriceIndex fetches the annual consumer price index
+// (FP.CPI.TOTL) of the country identified by the ISO 3166 alpha-3 code
+func GetConsumerPriceIndex(country string) ([]*cpi.CPI, error) {
+	log.Infof("Fetching Consumer Price Index of %s from World Bank", country)
+	url := fmt.Sprintf("%s/country/%s/indicator/FP.CPI.TOTL?format=json&per_page=200", baseURL, country)
+	respBytes, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	// the response is a pair of the page info and the values, errors
+	// are returned as a single message object
+	var response []json.RawMessage
+	err = json.Unmarshal(respBytes, &response)
+	if err != nil || len(response) < 2 {
+		return nil, errors.New(fmt.Sprintf("Unexpected response from World Bank: %s", string(respBytes)))
+	}
+
+	var values []indicatorValue
+	err = json.Unmarshal(response[1], &values)
+	if err != nil {
+		return nil, err
+	}
+
+	var cpis []*cpi.CPI
+	for _, v := range values {
+		year, err := strconv.Atoi(v.Date)
+		if err != nil || v.Value == nil {
+			continue
+		}
+		cpis = append(cpis, &cpi.CPI{Country: country, Year: year, Index: *v.Value})
+	}
+
+	if len(cpis) == 0 {
+		return nil, errors.New(fmt.Sprintf("Consumer Price Index of %s is not available from World Bank", country))
+	}
+	return cpis, nil
+}
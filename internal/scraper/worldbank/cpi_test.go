@@ -0,0 +1,48 @@
+package worldbank
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConsumerPriceIndex(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/cpi.json")
+	assert.NoError(t, err)
+
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	original := baseURL
+	baseURL = server.URL
+	defer func() { baseURL = original }()
+
+	cpis, err := GetConsumerPriceIndex("IND")
+	assert.NoError(t, err)
+	assert.Equal(t, "/country/IND/indicator/FP.CPI.TOTL", requested)
+	assert.Len(t, cpis, 2)
+	assert.Equal(t, 2024, cpis[0].Year)
+	assert.Equal(t, "227.62", cpis[0].Index.String())
+	assert.Equal(t, "IND", cpis[1].Country)
+}
+
+func TestGetConsumerPriceIndexError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"message":[{"id":"120","key":"Invalid value","value":"The provided parameter value is not valid"}]}]`))
+	}))
+	defer server.Close()
+
+	original := baseURL
+	baseURL = server.URL
+	defer func() { baseURL = original }()
+
+	_, err := GetConsumerPriceIndex("XYZ")
+	assert.Error(t, err)
+}
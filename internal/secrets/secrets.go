@@ -0,0 +1,205 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+)
+
+const (
+	// EncryptedPrefix marks a value encrypted inline, like the tokens
+	// stored in the database
+	EncryptedPrefix = "enc:"
+	// ReferencePrefix marks a value that refers to a named secret in the
+	// secrets store, like secret:kite.primary.password
+	ReferencePrefix = "secret:"
+
+	modeKeyFile    byte = 1
+	modePassphrase byte = 2
+
+	keySize   = 32
+	nonceSize = 24
+	saltSize  = 16
+)
+
+var ErrNoKey = errors.New("no secrets key found, set PAISA_SECRETS_PASSPHRASE or store a secret with paisa secrets set to generate a key file")
+
+// derived keys are cached by salt, scrypt is deliberately slow
+var (
+	derivedKeys   = make(map[string]*[keySize]byte)
+	derivedKeysMu sync.Mutex
+)
+
+func passphrase() string {
+	return os.Getenv("PAISA_SECRETS_PASSPHRASE")
+}
+
+// KeyFilePath returns the location of the key file, it can be overridden
+// with the PAISA_SECRETS_KEYFILE environment variable.
+func KeyFilePath() string {
+	if path := os.Getenv("PAISA_SECRETS_KEYFILE"); path != "" {
+		return path
+	}
+	return filepath.Join(config.GetConfigDir(), "paisa.key")
+}
+
+func readKeyFile() (*[keySize]byte, error) {
+	content, err := os.ReadFile(KeyFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoKey
+		}
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	decoded, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(decoded) != keySize {
+		return nil, fmt.Errorf("invalid key file %s, expected %d hex encoded bytes", KeyFilePath(), keySize)
+	}
+
+	var key [keySize]byte
+	copy(key[:], decoded)
+	return &key, nil
+}
+
+// ensureKeyFile returns the key from the key file, generating a new one
+// if it doesn't exist yet.
+func ensureKeyFile() (*[keySize]byte, error) {
+	key, err := readKeyFile()
+	if err != ErrNoKey {
+		return key, err
+	}
+
+	key = new([keySize]byte)
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(KeyFilePath(), []byte(hex.EncodeToString(key[:])+"\n"), 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key file: %w", err)
+	}
+
+	return key, nil
+}
+
+func deriveKey(salt []byte) (*[keySize]byte, error) {
+	pass := passphrase()
+	if pass == "" {
+		return nil, ErrNoKey
+	}
+
+	derivedKeysMu.Lock()
+	defer derivedKeysMu.Unlock()
+
+	cacheKey := pass + string(salt)
+	if key, ok := derivedKeys[cacheKey]; ok {
+		return key, nil
+	}
+
+	derived, err := scrypt.Key([]byte(pass), salt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	var key [keySize]byte
+	copy(key[:], derived)
+	derivedKeys[cacheKey] = &key
+	return &key, nil
+}
+
+// Encrypt encrypts the value with the master passphrase if one is set,
+// otherwise with the key file.
+func Encrypt(plaintext string) (string, error) {
+	var header []byte
+	var key *[keySize]byte
+	var err error
+
+	if passphrase() != "" {
+		salt := make([]byte, saltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return "", err
+		}
+		key, err = deriveKey(salt)
+		header = append([]byte{modePassphrase}, salt...)
+	} else {
+		key, err = ensureKeyFile()
+		header = []byte{modeKeyFile}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var nonce [nonceSize]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return "", err
+	}
+
+	sealed := secretbox.Seal(append(header, nonce[:]...), []byte(plaintext), &nonce, key)
+	return EncryptedPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt
+func Decrypt(value string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil || len(data) < 1 {
+		return "", fmt.Errorf("invalid encrypted value")
+	}
+
+	var key *[keySize]byte
+	mode, data := data[0], data[1:]
+	switch mode {
+	case modeKeyFile:
+		key, err = readKeyFile()
+	case modePassphrase:
+		if len(data) < saltSize {
+			return "", fmt.Errorf("invalid encrypted value")
+		}
+		key, err = deriveKey(data[:saltSize])
+		data = data[saltSize:]
+	default:
+		return "", fmt.Errorf("unknown encryption mode %d", mode)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < nonceSize+secretbox.Overhead {
+		return "", fmt.Errorf("invalid encrypted value")
+	}
+
+	var nonce [nonceSize]byte
+	copy(nonce[:], data[:nonceSize])
+	plaintext, ok := secretbox.Open(nil, data[nonceSize:], &nonce, key)
+	if !ok {
+		return "", fmt.Errorf("failed to decrypt value, wrong passphrase or key file")
+	}
+
+	return string(plaintext), nil
+}
+
+// Resolve returns the plaintext of a configured value. Values may refer
+// to a named secret, be encrypted inline or be plain text, which is
+// returned as is for backward compatibility.
+func Resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, ReferencePrefix):
+		return Get(strings.TrimPrefix(value, ReferencePrefix))
+	case strings.HasPrefix(value, EncryptedPrefix):
+		return Decrypt(value)
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptWithKeyFile(t *testing.T) {
+	t.Setenv("PAISA_SECRETS_PASSPHRASE", "")
+	t.Setenv("PAISA_SECRETS_KEYFILE", filepath.Join(t.TempDir(), "paisa.key"))
+
+	_, err := Decrypt("enc:AQ")
+	assert.Error(t, err)
+
+	encrypted, err := Encrypt("totp-seed")
+	assert.NoError(t, err)
+	assert.NotContains(t, encrypted, "totp-seed")
+
+	plaintext, err := Resolve(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "totp-seed", plaintext)
+
+	t.Setenv("PAISA_SECRETS_KEYFILE", filepath.Join(t.TempDir(), "other.key"))
+	_, err = Decrypt(encrypted)
+	assert.ErrorIs(t, err, ErrNoKey)
+}
+
+func TestEncryptWithPassphrase(t *testing.T) {
+	t.Setenv("PAISA_SECRETS_PASSPHRASE", "correct horse")
+
+	encrypted, err := Encrypt("api-secret")
+	assert.NoError(t, err)
+
+	plaintext, err := Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "api-secret", plaintext)
+
+	t.Setenv("PAISA_SECRETS_PASSPHRASE", "wrong horse")
+	_, err = Decrypt(encrypted)
+	assert.Error(t, err)
+}
+
+func TestResolvePlaintext(t *testing.T) {
+	plaintext, err := Resolve("not-encrypted")
+	assert.NoError(t, err)
+	assert.Equal(t, "not-encrypted", plaintext)
+}
@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+)
+
+// StorePath returns the location of the file holding the named secrets,
+// the values are stored encrypted.
+func StorePath() string {
+	return filepath.Join(config.GetConfigDir(), "secrets.yaml")
+}
+
+func readStore() (map[string]string, error) {
+	store := make(map[string]string)
+
+	content, err := os.ReadFile(StorePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read secrets store: %w", err)
+	}
+
+	err = yaml.Unmarshal(content, &store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse secrets store: %w", err)
+	}
+
+	return store, nil
+}
+
+// Set encrypts the value and saves it in the secrets store under the
+// given name
+func Set(name string, value string) error {
+	store, err := readStore()
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := Encrypt(value)
+	if err != nil {
+		return err
+	}
+	store[name] = encrypted
+
+	content, err := yaml.Marshal(store)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(StorePath(), content, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write secrets store: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the decrypted value of the named secret
+func Get(name string) (string, error) {
+	store, err := readStore()
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, ok := store[name]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", name)
+	}
+
+	return Decrypt(encrypted)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ananthakumaran/paisa/internal/background"
 	"github.com/ananthakumaran/paisa/internal/background/kite"
 	"github.com/ananthakumaran/paisa/internal/background/prices"
+	"github.com/ananthakumaran/paisa/internal/events"
 	"github.com/ananthakumaran/paisa/internal/model/task_execution"
 )
 
@@ -94,9 +95,14 @@ func RunKiteTradesTask(db *gorm.DB) gin.H {
 			log.Errorf("Failed to update last run time for task %s: %v", task.Name(), err)
 		}
 
+		start := time.Now()
+		events.Publish(events.TaskStarted, events.TaskData{Name: task.Name()})
+
 		if err := task.Run(context.Background(), db); err != nil {
 			log.Errorf("Manual KITE trades task failed: %v", err)
+			events.Publish(events.TaskFailed, events.TaskData{Name: task.Name(), Duration: time.Since(start).Seconds(), Error: err.Error()})
 		} else {
+			events.Publish(events.TaskCompleted, events.TaskData{Name: task.Name(), Duration: time.Since(start).Seconds()})
 			// Update the last successful run time
 			if err := task_execution.UpdateLastSuccessfulRun(db, task.Name()); err != nil {
 				log.Errorf("Failed to update last successful run time for task %s: %v", task.Name(), err)
@@ -118,9 +124,14 @@ func RunPriceUpdateTask(db *gorm.DB) gin.H {
 			log.Errorf("Failed to update last run time for task %s: %v", task.Name(), err)
 		}
 
+		start := time.Now()
+		events.Publish(events.TaskStarted, events.TaskData{Name: task.Name()})
+
 		if err := task.Run(context.Background(), db); err != nil {
 			log.Errorf("Manual price update task failed: %v", err)
+			events.Publish(events.TaskFailed, events.TaskData{Name: task.Name(), Duration: time.Since(start).Seconds(), Error: err.Error()})
 		} else {
+			events.Publish(events.TaskCompleted, events.TaskData{Name: task.Name(), Duration: time.Since(start).Seconds()})
 			// Update the last successful run time
 			if err := task_execution.UpdateLastSuccessfulRun(db, task.Name()); err != nil {
 				log.Errorf("Failed to update last successful run time for task %s: %v", task.Name(), err)
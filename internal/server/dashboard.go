@@ -17,6 +17,6 @@ func GetDashboard(db *gorm.DB) gin.H {
 		"transactionSequences": ComputeRecurringTransactions(query.Init(db).All()),
 		"transactions":         GetLatestTransactions(db),
 		"budget":               GetCurrentBudget(db),
-		"goalSummaries":        goal.GetGoalSummaries(db),
+		"goalSummaries":        goal.GetGoalSummaries(db, nil),
 	}
 }
@@ -3,14 +3,21 @@ package server
 import (
 	"errors"
 	"fmt"
+	"html"
 	"net/url"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/ananthakumaran/paisa/internal/accounting"
 	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/ledger"
+	"github.com/ananthakumaran/paisa/internal/model"
 	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/model/transaction"
 	"github.com/ananthakumaran/paisa/internal/query"
+	"github.com/ananthakumaran/paisa/internal/scraper"
+	"github.com/ananthakumaran/paisa/internal/server/liabilities"
 	"github.com/ananthakumaran/paisa/internal/service"
 	"github.com/ananthakumaran/paisa/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -31,6 +38,7 @@ type Issue struct {
 	Summary     string `json:"summary"`
 	Description string `json:"description"`
 	Details     string `json:"details"`
+	Fix         *Fix   `json:"fix,omitempty"`
 }
 
 type Rule struct {
@@ -79,15 +87,40 @@ func init() {
 				Level:       WARN,
 				Summary:     "Asset Accounts missing from Allocation Target",
 				Description: "Asset accounts are not part of any allocation target."},
-			Predicate: ruleAllocationTargetMissingAssetAccounts}}
+			Predicate: ruleAllocationTargetMissingAssetAccounts},
+		{
+			Issue: Issue{
+				Level:       ERROR,
+				Summary:     "Price Provider Failing",
+				Description: "The price provider of the commodity is missing or failed to fetch the price during the last update."},
+			Predicate: rulePriceProviderFailing},
+		{
+			Issue: Issue{
+				Level:       WARN,
+				Summary:     "Duplicate Transaction",
+				Description: "The transactions have the same amount, similar payee and are a few days apart. This usually happens when the same transaction is imported from multiple sources or entered by hand after an import."},
+			Predicate: ruleDuplicateTransaction},
+		{
+			Issue: Issue{
+				Level:       WARN,
+				Summary:     "Loan EMI Mismatch",
+				Description: "The principal and interest recorded for the EMI don't match the schedule of the loan. Installments before the first recorded EMI are not checked."},
+			Predicate: ruleLoanScheduleMismatch},
+		{
+			Issue: Issue{
+				Level:       WARN,
+				Summary:     "Unsupported Transaction",
+				Description: "The native parser ignores the periodic (~) and automated (=) transactions, the budget and the forecast won't include them. Use ledger or hledger as the <b>ledger_cli</b> to include them."},
+			Predicate: ruleNativeUnsupportedTransaction}}
 }
 
 func GetDiagnosis(db *gorm.DB) gin.H {
 	issues := make([]Issue, 0)
-	for _, rule := range rules {
+	for _, rule := range append(slices.Clone(rules), userRules()...) {
 		for _, error := range rule.Predicate(db) {
 			issue := rule.Issue
 			issue.Details = error.Error()
+			issue.Fix = fixOf(error)
 			issues = append(issues, issue)
 		}
 	}
@@ -133,12 +166,17 @@ func ruleNonDebitAccount(db *gorm.DB) []error {
 func ruleExchangePriceMissing(db *gorm.DB) []error {
 	errs := make([]error, 0)
 	postings := query.Init(db).Desc().All()
+	lines := journalLines{}
 
 	for _, p := range postings {
 		if !utils.IsCurrency(p.Commodity) {
 			externalPrice := service.GetUnitPrice(db, p.Commodity, p.Date)
 			if externalPrice.CommodityName != "" && externalPrice.CommodityName != p.Commodity {
-				errs = append(errs, errors.New(fmt.Sprintf("Exchange price from <b>%s</b> to your default currency <b>%s</b> is not specified for posting %s", p.Commodity, config.DefaultCurrency(), formatPosting(p))))
+				err := errors.New(fmt.Sprintf("Exchange price from <b>%s</b> to your default currency <b>%s</b> is not specified for posting %s", p.Commodity, config.DefaultCurrency(), formatPosting(p)))
+				if fix := exchangePriceFix(db, lines, p); fix != nil {
+					err = withFix(err, *fix)
+				}
+				errs = append(errs, err)
 			}
 		}
 	}
@@ -148,15 +186,21 @@ func ruleExchangePriceMissing(db *gorm.DB) []error {
 func ruleJournalPriceMismatch(db *gorm.DB) []error {
 	errs := make([]error, 0)
 	postings := query.Init(db).Desc().All()
+	lines := journalLines{}
 	for _, p := range postings {
 		if !utils.IsCurrency(p.Commodity) {
 			externalPrice := service.GetUnitPrice(db, p.Commodity, p.Date)
 			diff := externalPrice.Value.Sub(p.Price()).Abs()
 			if externalPrice.CommodityName == p.Commodity &&
 				externalPrice.CommodityType != config.Unknown &&
+				externalPrice.CommodityType != config.Currency &&
 				!service.IsSellWithCapitalGains(db, p) &&
 				diff.GreaterThanOrEqual(decimal.NewFromFloat(0.0001)) {
-				errs = append(errs, errors.New(fmt.Sprintf("The price specified in your posting %s doesn't match the price <b>%.4f</b> (%s) fetched from external system", formatPosting(p), externalPrice.Value.InexactFloat64(), externalPrice.Date.Format(DATE_FORMAT))))
+				err := errors.New(fmt.Sprintf("The price specified in your posting %s doesn't match the price <b>%.4f</b> (%s) fetched from external system", formatPosting(p), externalPrice.Value.InexactFloat64(), externalPrice.Date.Format(DATE_FORMAT)))
+				if fix := unitPriceFix(lines, p, externalPrice.Value); fix != nil {
+					err = withFix(err, *fix)
+				}
+				errs = append(errs, err)
 			}
 		}
 	}
@@ -208,8 +252,90 @@ func ruleAllocationTargetMissingAssetAccounts(db *gorm.DB) []error {
 	}
 
 	if len(ignoredAccounts) > 0 {
-		errs = append(errs, errors.New(fmt.Sprintf("The following asset accounts are not part of any asset allocation target: <b>%s</b>", strings.Join(ignoredAccounts, ", "))))
+		err := errors.New(fmt.Sprintf("The following asset accounts are not part of any asset allocation target: <b>%s</b>", strings.Join(ignoredAccounts, ", ")))
+		if fix := allocationTargetFix(db, ignoredAccounts); fix != nil {
+			err = withFix(err, *fix)
+		}
+		errs = append(errs, err)
 	}
 
 	return errs
 }
+
+func rulePriceProviderFailing(db *gorm.DB) []error {
+	errs := make([]error, 0)
+	fetchErrors := model.PriceFetchErrors()
+	for _, commodity := range config.GetConfig().Commodities {
+		message, ok := fetchErrors[commodity.Name]
+		if !ok {
+			continue
+		}
+
+		// the provider codes are validated when the config is loaded
+		provider, err := scraper.GetProviderByCode(commodity.Price.Provider)
+		if err != nil {
+			continue
+		}
+
+		errs = append(errs, errors.New(fmt.Sprintf("Failed to fetch the price of <b>%s</b> from <b>%s</b>: %s", html.EscapeString(commodity.Name), html.EscapeString(provider.Label()), html.EscapeString(message))))
+	}
+	return errs
+}
+
+func ruleDuplicateTransaction(db *gorm.DB) []error {
+	errs := make([]error, 0)
+	transactions := transaction.Build(query.Init(db).All())
+	for _, pair := range transaction.FindDuplicates(transactions, transaction.DefaultDuplicateOptions) {
+		errs = append(errs, errors.New(fmt.Sprintf("%s and %s", formatTransaction(pair.First), formatTransaction(pair.Second))))
+	}
+	return errs
+}
+
+func ruleNativeUnsupportedTransaction(db *gorm.DB) []error {
+	errs := make([]error, 0)
+	native, ok := ledger.Cli().(ledger.Native)
+	if !ok {
+		return errs
+	}
+
+	unsupported, err := native.Unsupported(config.GetJournalPath())
+	if err != nil {
+		return []error{err}
+	}
+
+	for _, u := range unsupported {
+		errs = append(errs, errors.New(html.EscapeString(u)))
+	}
+	return errs
+}
+
+func ruleLoanScheduleMismatch(db *gorm.DB) []error {
+	errs := make([]error, 0)
+	for _, schedule := range liabilities.LoanSchedules(db) {
+		if schedule.Error != "" {
+			errs = append(errs, errors.New(html.EscapeString(schedule.Error)))
+			continue
+		}
+
+		tracked := false
+		for _, installment := range schedule.Installments {
+			switch installment.Status {
+			case liabilities.Matched:
+				tracked = true
+			case liabilities.Mismatch:
+				tracked = true
+				errs = append(errs, errors.New(fmt.Sprintf("EMI of <b>%s</b> on %s: principal <b>%.2f</b> and interest <b>%.2f</b> expected, principal %.2f and interest %.2f recorded", html.EscapeString(schedule.Name), installment.Date.Format(DATE_FORMAT), installment.Principal.InexactFloat64(), installment.Interest.InexactFloat64(), installment.ActualPrincipal.InexactFloat64(), installment.ActualInterest.InexactFloat64())))
+			case liabilities.Missing:
+				if tracked {
+					errs = append(errs, errors.New(fmt.Sprintf("EMI of <b>%s</b> on %s is not recorded: principal <b>%.2f</b> and interest <b>%.2f</b> expected", html.EscapeString(schedule.Name), installment.Date.Format(DATE_FORMAT), installment.Principal.InexactFloat64(), installment.Interest.InexactFloat64())))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func formatTransaction(t transaction.Transaction) string {
+	transactionUrl := fmt.Sprintf("/ledger/editor/%s#%d", url.PathEscape(t.FileName), t.BeginLine)
+	return fmt.Sprintf("<a href=\"%s\">%s %s (%s:%d)</a>", transactionUrl, t.Date.Format(DATE_FORMAT), html.EscapeString(t.Payee), html.EscapeString(t.FileName), t.BeginLine)
+}
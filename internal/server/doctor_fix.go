@@ -0,0 +1,306 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/ananthakumaran/paisa/internal/service"
+	"github.com/ananthakumaran/paisa/internal/utils"
+)
+
+// Fix is a mechanical change which resolves an issue, either a patch
+// to one of the journal files or an addition to the config
+type Fix struct {
+	Summary string       `json:"summary"`
+	File    *FilePatch   `json:"file,omitempty"`
+	Config  *ConfigPatch `json:"config,omitempty"`
+}
+
+// FilePatch replaces the lines from Start to End (1 based and
+// inclusive) of the file with the replacement. Original is the content
+// of the lines when the patch was generated, the patch is rejected if
+// the file has changed since then.
+type FilePatch struct {
+	Name        string `json:"name"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Original    string `json:"original"`
+	Replacement string `json:"replacement"`
+}
+
+// ConfigPatch appends the value to the list under the key
+type ConfigPatch struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+type fixableError struct {
+	error
+	fix Fix
+}
+
+func withFix(err error, fix Fix) error {
+	return fixableError{error: err, fix: fix}
+}
+
+func fixOf(err error) *Fix {
+	var fixable fixableError
+	if errors.As(err, &fixable) {
+		return &fixable.fix
+	}
+	return nil
+}
+
+func ApplyFix(db *gorm.DB, fix Fix) gin.H {
+	if fix.File != nil {
+		path := config.GetJournalPath()
+		filePath, err := utils.BuildSubPath(filepath.Dir(path), fix.File.Name)
+		if err != nil {
+			return gin.H{"saved": false, "message": "Invalid file name"}
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			log.Warn(err)
+			return gin.H{"saved": false, "message": "Failed to read file"}
+		}
+
+		patched, err := applyFilePatch(string(content), *fix.File)
+		if err != nil {
+			return gin.H{"saved": false, "message": err.Error()}
+		}
+
+		return SaveFile(db, LedgerFile{Name: fix.File.Name, Content: patched})
+	}
+
+	if fix.Config != nil {
+		content, err := yaml.Marshal(config.GetConfig())
+		if err != nil {
+			return gin.H{"saved": false, "message": err.Error()}
+		}
+
+		patched, err := applyConfigPatch(content, *fix.Config)
+		if err != nil {
+			return gin.H{"saved": false, "message": err.Error()}
+		}
+
+		err = config.SaveConfig(patched)
+		if err != nil {
+			return gin.H{"saved": false, "message": err.Error()}
+		}
+
+		return gin.H{"saved": true}
+	}
+
+	return gin.H{"saved": false, "message": "Nothing to fix"}
+}
+
+func applyFilePatch(content string, patch FilePatch) (string, error) {
+	lines := strings.Split(content, "\n")
+	if patch.Start < 1 || patch.End < patch.Start || patch.End > len(lines) {
+		return "", errors.New(fmt.Sprintf("Invalid line range %d-%d", patch.Start, patch.End))
+	}
+
+	if strings.Join(lines[patch.Start-1:patch.End], "\n") != patch.Original {
+		return "", errors.New("The file has changed since the fix was suggested, please run the diagnosis again")
+	}
+
+	patched := append(slices.Clone(lines[:patch.Start-1]), patch.Replacement)
+	patched = append(patched, lines[patch.End:]...)
+	return strings.Join(patched, "\n"), nil
+}
+
+func applyConfigPatch(content []byte, patch ConfigPatch) ([]byte, error) {
+	var document map[string]any
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	if document == nil {
+		document = make(map[string]any)
+	}
+
+	var list []any
+	switch existing := document[patch.Key].(type) {
+	case nil:
+		list = []any{}
+	case []any:
+		list = existing
+	default:
+		return nil, errors.New(fmt.Sprintf("%s is not a list", patch.Key))
+	}
+
+	document[patch.Key] = append(list, patch.Value)
+	return yaml.Marshal(document)
+}
+
+// journalLines caches the lines of the journal files read while
+// generating the fixes
+type journalLines map[string][]string
+
+func (j journalLines) get(name string) []string {
+	if lines, ok := j[name]; ok {
+		return lines
+	}
+
+	var lines []string
+	filePath, err := utils.BuildSubPath(filepath.Dir(config.GetJournalPath()), name)
+	if err == nil {
+		content, err := os.ReadFile(filePath)
+		if err == nil {
+			lines = strings.Split(string(content), "\n")
+		}
+	}
+	j[name] = lines
+	return lines
+}
+
+func insertPriceFix(lines journalLines, p posting.Posting, date time.Time, value decimal.Decimal, summary string) *Fix {
+	content := lines.get(p.FileName)
+	line := int(p.TransactionBeginLine)
+	if line < 1 || line > len(content) {
+		return nil
+	}
+
+	original := content[line-1]
+	return &Fix{
+		Summary: summary,
+		File: &FilePatch{
+			Name:        p.FileName,
+			Start:       line,
+			End:         line,
+			Original:    original,
+			Replacement: formatPriceDirective(date, p.Commodity, value) + "\n" + original,
+		},
+	}
+}
+
+func formatPriceDirective(date time.Time, commodity string, value decimal.Decimal) string {
+	if config.GetConfig().LedgerCli == "beancount" {
+		return fmt.Sprintf("%s price %s %s %s", date.Format("2006-01-02"), commodity, value.Round(4).String(), config.DefaultCurrency())
+	}
+	return fmt.Sprintf("P %s %s %s %s", date.Format("2006/01/02"), commodity, value.Round(4).String(), config.DefaultCurrency())
+}
+
+// exchangePriceFix uses the cost of the posting as the exchange price
+// if available, otherwise the nearest known price after the posting
+func exchangePriceFix(db *gorm.DB, lines journalLines, p posting.Posting) *Fix {
+	if !p.Quantity.IsZero() && !p.Quantity.Equal(p.Amount) {
+		return insertPriceFix(lines, p, p.Date, p.Price(), fmt.Sprintf("Add the price of %s from the cost of the posting", p.Commodity))
+	}
+
+	// prices are sorted in descending order of the date
+	next, _, ok := lo.FindLastIndexOf(service.GetAllPrices(db, p.Commodity), func(pc price.Price) bool {
+		return pc.CommodityName == p.Commodity && !pc.Value.IsZero() && !pc.Date.Before(p.Date)
+	})
+	if !ok {
+		return nil
+	}
+
+	return insertPriceFix(lines, p, p.Date, next.Value, fmt.Sprintf("Add the price of %s using the price on %s", p.Commodity, next.Date.Format(DATE_FORMAT)))
+}
+
+var unitPriceRegex = regexp.MustCompile(`(\s@\s*)([^\d\s;@-]*\s*)(\d[\d,]*(?:\.\d+)?)(\s*[^\s;]*)`)
+
+// replaceUnitPrice replaces the per unit cost (@) of the posting line,
+// only when the cost is in the default currency
+func replaceUnitPrice(line string, value decimal.Decimal) (string, bool) {
+	match := unitPriceRegex.FindStringSubmatchIndex(line)
+	if match == nil || strings.Contains(line, "@@") {
+		return "", false
+	}
+
+	currency := strings.TrimSpace(line[match[4]:match[5]] + line[match[8]:match[9]])
+	if currency != config.DefaultCurrency() {
+		return "", false
+	}
+
+	return line[:match[6]] + value.Round(4).String() + line[match[7]:], true
+}
+
+func unitPriceFix(lines journalLines, p posting.Posting, value decimal.Decimal) *Fix {
+	content := lines.get(p.FileName)
+	begin := max(int(p.TransactionBeginLine), 1)
+	end := min(int(p.TransactionEndLine), len(content))
+
+	var found []int
+	for i := begin; i <= end; i++ {
+		fields := strings.Fields(strings.TrimLeft(content[i-1], " \t*!"))
+		if len(fields) > 0 && fields[0] == p.Account && strings.Contains(content[i-1], "@") {
+			found = append(found, i)
+		}
+	}
+
+	if len(found) != 1 {
+		return nil
+	}
+
+	line := found[0]
+	replacement, ok := replaceUnitPrice(content[line-1], value)
+	if !ok {
+		return nil
+	}
+
+	return &Fix{
+		Summary: fmt.Sprintf("Change the unit price to %s", value.Round(4).String()),
+		File: &FilePatch{
+			Name:        p.FileName,
+			Start:       line,
+			End:         line,
+			Original:    content[line-1],
+			Replacement: replacement,
+		},
+	}
+}
+
+// allocationTargetFix adds a target for the missing accounts with their
+// current share of the assets, so the allocation stays the same
+func allocationTargetFix(db *gorm.DB, accounts []string) *Fix {
+	name := "Unallocated"
+	if lo.SomeBy(config.GetConfig().AllocationTargets, func(target config.AllocationTarget) bool { return target.Name == name }) {
+		return nil
+	}
+
+	total, missing := decimal.Zero, decimal.Zero
+	now := utils.EndOfToday()
+	var postings []posting.Posting
+	db.Where("account like ?", "Assets:%").Find(&postings)
+	for _, p := range postings {
+		amount := service.GetMarketPrice(db, p, now)
+		total = total.Add(amount)
+		if lo.Contains(accounts, p.Account) {
+			missing = missing.Add(amount)
+		}
+	}
+
+	target := 1.0
+	if total.IsPositive() {
+		target = math.Min(math.Max(math.Round(missing.Div(total).InexactFloat64()*100), 1), 100)
+	}
+
+	return &Fix{
+		Summary: fmt.Sprintf("Add an allocation target named %s with %.0f%% target", name, target),
+		Config: &ConfigPatch{
+			Key:   "allocation_targets",
+			Value: config.AllocationTarget{Name: name, Target: target, Accounts: accounts},
+		},
+	}
+}
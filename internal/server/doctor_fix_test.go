@@ -0,0 +1,81 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+)
+
+func TestApplyFilePatch(t *testing.T) {
+	content := "2023/01/03 Buy NIFTY\n    Assets:Equity:NIFTY    10 NIFTY @ 110 INR\n    Assets:Checking\n"
+	patched, err := applyFilePatch(content, FilePatch{
+		Name:        "main.ledger",
+		Start:       2,
+		End:         2,
+		Original:    "    Assets:Equity:NIFTY    10 NIFTY @ 110 INR",
+		Replacement: "    Assets:Equity:NIFTY    10 NIFTY @ 105.5 INR",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2023/01/03 Buy NIFTY\n    Assets:Equity:NIFTY    10 NIFTY @ 105.5 INR\n    Assets:Checking\n", patched)
+
+	patched, err = applyFilePatch(content, FilePatch{
+		Start:       1,
+		End:         1,
+		Original:    "2023/01/03 Buy NIFTY",
+		Replacement: "P 2023/01/03 NIFTY 110 INR\n2023/01/03 Buy NIFTY",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "P 2023/01/03 NIFTY 110 INR\n"+content, patched)
+
+	_, err = applyFilePatch(content, FilePatch{Start: 2, End: 2, Original: "    Assets:Equity:NIFTY    10 NIFTY @ 100 INR"})
+	assert.Error(t, err)
+
+	_, err = applyFilePatch(content, FilePatch{Start: 3, End: 10})
+	assert.Error(t, err)
+}
+
+func TestReplaceUnitPrice(t *testing.T) {
+	assert.NoError(t, config.LoadConfig([]byte("journal_path: main.ledger\ndb_path: paisa.db\n"), ""))
+	price := decimal.NewFromFloat(105.12345)
+
+	line, ok := replaceUnitPrice("    Assets:Equity:NIFTY    10 NIFTY @ 110 INR ; note", price)
+	assert.True(t, ok)
+	assert.Equal(t, "    Assets:Equity:NIFTY    10 NIFTY @ 105.1235 INR ; note", line)
+
+	line, ok = replaceUnitPrice("    Assets:Equity:NIFTY    10 NIFTY @ INR 1,100.50", price)
+	assert.True(t, ok)
+	assert.Equal(t, "    Assets:Equity:NIFTY    10 NIFTY @ INR 105.1235", line)
+
+	_, ok = replaceUnitPrice("    Assets:Equity:AAPL    10 AAPL @ 110 USD", price)
+	assert.False(t, ok)
+
+	_, ok = replaceUnitPrice("    Assets:Equity:NIFTY    10 NIFTY @@ 1100 INR", price)
+	assert.False(t, ok)
+}
+
+func TestApplyConfigPatch(t *testing.T) {
+	content := []byte("journal_path: main.ledger\nallocation_targets:\n  - name: Debt\n    target: 30\n    accounts:\n      - Assets:Debt:*\n")
+	patched, err := applyConfigPatch(content, ConfigPatch{
+		Key:   "allocation_targets",
+		Value: config.AllocationTarget{Name: "Unallocated", Target: 10, Accounts: []string{"Assets:Gold"}},
+	})
+	assert.NoError(t, err)
+
+	var result struct {
+		JournalPath       string                    `yaml:"journal_path"`
+		AllocationTargets []config.AllocationTarget `yaml:"allocation_targets"`
+	}
+	assert.NoError(t, yaml.Unmarshal(patched, &result))
+	assert.Equal(t, "main.ledger", result.JournalPath)
+	assert.Equal(t, []config.AllocationTarget{
+		{Name: "Debt", Target: 30, Accounts: []string{"Assets:Debt:*"}},
+		{Name: "Unallocated", Target: 10, Accounts: []string{"Assets:Gold"}},
+	}, result.AllocationTargets)
+
+	_, err = applyConfigPatch(content, ConfigPatch{Key: "journal_path", Value: "other.ledger"})
+	assert.Error(t, err)
+}
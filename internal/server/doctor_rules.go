@@ -0,0 +1,120 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"html"
+
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/query"
+	"github.com/ananthakumaran/paisa/internal/service"
+)
+
+// userRules builds the rules configured under doctor_rules, they are
+// built on every diagnosis as the config could change in between
+func userRules() []Rule {
+	return lo.Map(config.GetConfig().DoctorRules, func(rule config.DoctorRule, _ int) Rule {
+		level := WARN
+		if rule.Level == "error" {
+			level = ERROR
+		}
+
+		description := rule.Description
+		if description == "" {
+			description = describeDoctorRule(rule)
+		}
+
+		return Rule{
+			Issue: Issue{Level: level, Summary: rule.Name, Description: html.EscapeString(description)},
+			Predicate: func(db *gorm.DB) []error {
+				postings := query.Init(db).AccountPrefix(rule.Account).UntilToday().All()
+				postings = service.PopulateMarketPrice(db, postings)
+				return checkDoctorRule(rule, postings)
+			}}
+	})
+}
+
+func describeDoctorRule(rule config.DoctorRule) string {
+	switch rule.Check {
+	case "balance":
+		return fmt.Sprintf("Balance of %s should be %s %s", rule.Account, rule.Operator, formatRuleValue(rule.Value))
+	case "monthly_total":
+		return fmt.Sprintf("Monthly total of %s should be %s %s", rule.Account, rule.Operator, formatRuleValue(rule.Value))
+	case "no_postings":
+		return fmt.Sprintf("%s should not have any postings", rule.Account)
+	}
+	return ""
+}
+
+func formatRuleValue(value float64) string {
+	return decimal.NewFromFloat(value).String()
+}
+
+func checkDoctorRule(rule config.DoctorRule, postings []posting.Posting) []error {
+	errs := make([]error, 0)
+	account := html.EscapeString(rule.Account)
+
+	switch rule.Check {
+	case "balance":
+		// only the current balance is checked, at the market value
+		balance := lo.Reduce(postings, func(balance decimal.Decimal, p posting.Posting, _ int) decimal.Decimal {
+			return balance.Add(p.MarketAmount)
+		}, decimal.Zero)
+		ok, err := compareRuleValue(balance, rule.Operator, rule.Value)
+		if err != nil {
+			return []error{err}
+		}
+
+		if !ok {
+			errs = append(errs, errors.New(fmt.Sprintf("Balance of <b>%s</b> is <b>%.2f</b>, expected %s %s", account, balance.InexactFloat64(), html.EscapeString(rule.Operator), formatRuleValue(rule.Value))))
+		}
+
+	case "monthly_total":
+		byMonth := lo.GroupBy(postings, func(p posting.Posting) string { return p.Date.Format("2006-01") })
+		for _, month := range lo.Uniq(lo.Map(postings, func(p posting.Posting, _ int) string { return p.Date.Format("2006-01") })) {
+			total := lo.Reduce(byMonth[month], func(total decimal.Decimal, p posting.Posting, _ int) decimal.Decimal { return total.Add(p.Amount) }, decimal.Zero)
+			ok, err := compareRuleValue(total, rule.Operator, rule.Value)
+			if err != nil {
+				return []error{err}
+			}
+
+			if !ok {
+				errs = append(errs, errors.New(fmt.Sprintf("Total of <b>%s</b> was <b>%.2f</b> in %s, expected %s %s", account, total.InexactFloat64(), byMonth[month][0].Date.Format("Jan 2006"), html.EscapeString(rule.Operator), formatRuleValue(rule.Value))))
+			}
+		}
+
+	case "no_postings":
+		for _, p := range postings {
+			errs = append(errs, errors.New(fmt.Sprintf("Posting to <b>%s</b> %s", account, formatPosting(p))))
+		}
+
+	default:
+		errs = append(errs, errors.New(fmt.Sprintf("Unknown check <b>%s</b>", html.EscapeString(rule.Check))))
+	}
+
+	return errs
+}
+
+func compareRuleValue(amount decimal.Decimal, operator string, value float64) (bool, error) {
+	expected := decimal.NewFromFloat(value)
+	switch operator {
+	case "<":
+		return amount.LessThan(expected), nil
+	case "<=":
+		return amount.LessThanOrEqual(expected), nil
+	case ">":
+		return amount.GreaterThan(expected), nil
+	case ">=":
+		return amount.GreaterThanOrEqual(expected), nil
+	case "==":
+		return amount.Equal(expected), nil
+	case "!=":
+		return !amount.Equal(expected), nil
+	}
+	return false, errors.New(fmt.Sprintf("Unknown operator <b>%s</b>, should be one of &lt;, &lt;=, &gt;, &gt;=, ==, !=", html.EscapeString(operator)))
+}
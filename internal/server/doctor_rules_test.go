@@ -0,0 +1,69 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+)
+
+func rulePosting(date string, amount float64) posting.Posting {
+	d, _ := time.Parse("2006-01-02", date)
+	return posting.Posting{Date: d, Account: "Assets:Checking", Commodity: "INR", Amount: decimal.NewFromFloat(amount), Quantity: decimal.NewFromFloat(amount), MarketAmount: decimal.NewFromFloat(amount)}
+}
+
+func TestCheckDoctorRuleBalance(t *testing.T) {
+	rule := config.DoctorRule{Name: "Minimum", Account: "Assets:Checking", Check: "balance", Operator: ">=", Value: 10000}
+	postings := []posting.Posting{
+		rulePosting("2023-01-01", 15000),
+		rulePosting("2023-01-05", -8000),
+		rulePosting("2023-01-05", 5000),
+		rulePosting("2023-01-10", -4000),
+		rulePosting("2023-01-12", -2000),
+	}
+
+	errs := checkDoctorRule(rule, postings)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "<b>6000.00</b>")
+
+	// a breach in the past is not reported
+	postings = append(postings, rulePosting("2023-01-15", 5000))
+	assert.Empty(t, checkDoctorRule(rule, postings))
+
+	// the commodities are valued at the market price
+	units := rulePosting("2023-01-20", 1000)
+	units.Commodity = "NIFTY"
+	units.Quantity = decimal.NewFromInt(10)
+	units.MarketAmount = decimal.NewFromInt(500)
+	errs = checkDoctorRule(rule, append(postings, rulePosting("2023-01-20", -2000), units))
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "<b>9500.00</b>")
+}
+
+func TestCheckDoctorRuleMonthlyTotal(t *testing.T) {
+	rule := config.DoctorRule{Name: "Food", Account: "Expenses:Food", Check: "monthly_total", Operator: "<", Value: 1000}
+	postings := []posting.Posting{
+		rulePosting("2023-01-01", 600),
+		rulePosting("2023-01-20", 500),
+		rulePosting("2023-02-01", 900),
+	}
+
+	errs := checkDoctorRule(rule, postings)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "<b>1100.00</b> in Jan 2023")
+
+	rule.Operator = "~"
+	errs = checkDoctorRule(rule, postings)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "Unknown operator")
+}
+
+func TestCheckDoctorRuleNoPostings(t *testing.T) {
+	rule := config.DoctorRule{Name: "Unknown", Account: "Expenses:Unknown", Check: "no_postings"}
+	assert.Len(t, checkDoctorRule(rule, []posting.Posting{}), 0)
+	assert.Len(t, checkDoctorRule(rule, []posting.Posting{rulePosting("2023-01-01", 100)}), 1)
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/events"
 	"github.com/ananthakumaran/paisa/internal/ledger"
 	"github.com/ananthakumaran/paisa/internal/model/posting"
 	"github.com/ananthakumaran/paisa/internal/utils"
@@ -124,6 +125,7 @@ func SaveFile(db *gorm.DB, file LedgerFile) gin.H {
 		return gin.H{"errors": errors, "saved": false, "message": "Failed to write file"}
 	}
 
+	events.Publish(events.FileSaved, events.FileSaveData{Name: file.Name})
 	Sync(db, SyncRequest{Journal: true})
 
 	return gin.H{"errors": errors, "saved": true, "file": readLedgerFileWithVersions(dir, filePath)}
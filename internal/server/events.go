@@ -0,0 +1,45 @@
+package server
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ananthakumaran/paisa/internal/events"
+)
+
+// StreamEvents streams the published events to the client as server
+// sent events until the client disconnects
+func StreamEvents(c *gin.Context) {
+	ch, unsubscribe := events.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// keeps the connection open through proxies with idle timeouts
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+
+	c.Writer.WriteHeader(200)
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-ch:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-keepAlive.C:
+			_, err := w.Write([]byte(": keep-alive\n\n"))
+			return err == nil
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ananthakumaran/paisa/internal/model/posting"
 	"github.com/ananthakumaran/paisa/internal/model/transaction"
 	"github.com/ananthakumaran/paisa/internal/query"
+	"github.com/ananthakumaran/paisa/internal/service"
 	"github.com/ananthakumaran/paisa/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/samber/lo"
@@ -40,12 +41,12 @@ func GetCurrentExpense(db *gorm.DB) map[string][]posting.Posting {
 	return utils.GroupByMonth(expenses)
 }
 
-func GetExpense(db *gorm.DB) gin.H {
-	expenses := query.Init(db).Like("Expenses:%").NotAccountPrefix("Expenses:Tax").All()
-	incomes := query.Init(db).Like("Income:%").All()
-	investments := query.Init(db).Like("Assets:%").NotAccountPrefix("Assets:Checking").All()
-	taxes := query.Init(db).AccountPrefix("Expenses:Tax").All()
-	postings := query.Init(db).All()
+func GetExpense(db *gorm.DB, deflator *service.Deflator) gin.H {
+	expenses := deflator.DeflatePostings(query.Init(db).Like("Expenses:%").NotAccountPrefix("Expenses:Tax").All())
+	incomes := deflator.DeflatePostings(query.Init(db).Like("Income:%").All())
+	investments := deflator.DeflatePostings(query.Init(db).Like("Assets:%").NotAccountPrefix("Assets:Checking").All())
+	taxes := deflator.DeflatePostings(query.Init(db).AccountPrefix("Expenses:Tax").All())
+	postings := deflator.DeflatePostings(query.Init(db).All())
 
 	graph := make(map[string]Graph)
 	for fy, ps := range utils.GroupByFY(postings) {
@@ -29,9 +29,9 @@ type AccountGain struct {
 	Postings         []posting.Posting `json:"postings"`
 }
 
-func GetGain(db *gorm.DB) gin.H {
+func GetGain(db *gorm.DB, converter *service.Converter) gin.H {
 	postings := query.Init(db).Like("Assets:%", "Income:CapitalGains:%").NotAccountPrefix("Assets:Checking").All()
-	postings = service.PopulateMarketPrice(db, postings)
+	postings = converter.ConvertPostings(service.PopulateMarketPrice(db, postings))
 	byAccount := lo.GroupBy(postings, func(p posting.Posting) string {
 		if service.IsCapitalGains(p) {
 			return service.CapitalGainsSourceAccount(p.Account)
@@ -44,14 +44,14 @@ func GetGain(db *gorm.DB) gin.H {
 		gains = append(gains, Gain{Account: account, XIRR: service.XIRR(db, ps), Networth: computeNetworth(db, ps), Postings: ps})
 	}
 
-	return gin.H{"gain_breakdown": gains}
+	return gin.H{"gain_breakdown": gains, "currency": reportingCurrency(converter)}
 }
 
-func GetAccountGain(db *gorm.DB, account string) gin.H {
+func GetAccountGain(db *gorm.DB, account string, converter *service.Converter) gin.H {
 	capitalGainsAccount := strings.Replace(account, "Assets", "Income:CapitalGains", 1)
 	postings := query.Init(db).AccountPrefix(account, capitalGainsAccount).All()
-	postings = service.PopulateMarketPrice(db, postings)
-	gain := AccountGain{Account: account, XIRR: service.XIRR(db, postings), NetworthTimeline: computeNetworthTimeline(db, postings, accounting.IsLeafAccount(db, account)), Postings: postings}
+	postings = converter.ConvertPostings(service.PopulateMarketPrice(db, postings))
+	gain := AccountGain{Account: account, XIRR: service.XIRR(db, postings), NetworthTimeline: computeNetworthTimeline(db, postings, accounting.IsLeafAccount(db, account), converter), Postings: postings}
 
 	commodities := lo.Uniq(lo.Map(postings, func(p posting.Posting, _ int) string { return p.Commodity }))
 	var portfolio_groups PortfolioAllocationGroups
@@ -62,5 +62,5 @@ func GetAccountGain(db *gorm.DB, account string) gin.H {
 
 	assetBreakdown := assets.ComputeBreakdown(db, postings, false, account)
 
-	return gin.H{"gain_timeline_breakdown": gain, "portfolio_allocation": portfolio_groups, "asset_breakdown": assetBreakdown}
+	return gin.H{"gain_timeline_breakdown": gain, "portfolio_allocation": portfolio_groups, "asset_breakdown": assetBreakdown, "currency": reportingCurrency(converter)}
 }
@@ -1,7 +1,10 @@
 package goal
 
 import (
+	"strings"
+
 	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
 	"github.com/ananthakumaran/paisa/internal/query"
 	"github.com/ananthakumaran/paisa/internal/service"
 	"github.com/gin-gonic/gin"
@@ -19,32 +22,37 @@ type GoalSummary struct {
 	Target     decimal.Decimal `json:"target"`
 	TargetDate string          `json:"targetDate"`
 	Priority   int             `json:"priority"`
+
+	Projected                   decimal.Decimal `json:"projected"`
+	RequiredMonthlyContribution decimal.Decimal `json:"requiredMonthlyContribution"`
+	Status                      string          `json:"status"`
 }
 
-func GetGoalSummaries(db *gorm.DB) []GoalSummary {
+func GetGoalSummaries(db *gorm.DB, deflator *service.Deflator) []GoalSummary {
 	summaries := []GoalSummary{}
-	assetPostings := query.Init(db).Like("Assets:%").All()
-	assetPostings = service.PopulateMarketPrice(db, assetPostings)
+	postings := query.Init(db).Like("Assets:%", "Income:CapitalGains:%").All()
+	postings = service.PopulateMarketPrice(db, postings)
+	assetPostings := lo.Filter(postings, func(p posting.Posting, _ int) bool { return strings.HasPrefix(p.Account, "Assets:") })
 
 	for _, goal := range config.GetConfig().Goals.Retirement {
-		summaries = append(summaries, getRetirementSummary(db, assetPostings, goal))
+		summaries = append(summaries, getRetirementSummary(db, assetPostings, goal, deflator))
 	}
 
 	for _, goal := range config.GetConfig().Goals.Savings {
-		summaries = append(summaries, getSavingsSummary(db, assetPostings, goal))
+		summaries = append(summaries, getSavingsSummary(db, assetPostings, postings, goal, deflator))
 	}
 
 	return summaries
 }
 
-func GetGoalDetails(db *gorm.DB, goalType string, name string) gin.H {
+func GetGoalDetails(db *gorm.DB, goalType string, name string, deflator *service.Deflator) gin.H {
 	switch goalType {
 	case "retirement":
 		conf, _ := lo.Find(config.GetConfig().Goals.Retirement, func(conf config.RetirementGoal) bool { return conf.Name == name })
-		return getRetirementDetail(db, conf)
+		return getRetirementDetail(db, conf, deflator)
 	case "savings":
 		conf, _ := lo.Find(config.GetConfig().Goals.Savings, func(conf config.SavingsGoal) bool { return conf.Name == name })
-		return getSavingsDetail(db, conf)
+		return getSavingsDetail(db, conf, deflator)
 	}
 	return gin.H{}
 }
@@ -1,6 +1,14 @@
 package goal
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/ananthakumaran/paisa/internal/accounting"
 	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/ananthakumaran/paisa/internal/model/posting"
@@ -9,11 +17,12 @@ import (
 	"github.com/ananthakumaran/paisa/internal/service"
 	"github.com/ananthakumaran/paisa/internal/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
-func getRetirementSummary(db *gorm.DB, ps []posting.Posting, conf config.RetirementGoal) GoalSummary {
+func getRetirementSummary(db *gorm.DB, ps []posting.Posting, conf config.RetirementGoal, deflator *service.Deflator) GoalSummary {
 	savings := accounting.FilterByGlob(ps, conf.Savings)
 	savingsTotal := accounting.CurrentBalance(savings)
 
@@ -23,13 +32,14 @@ func getRetirementSummary(db *gorm.DB, ps []posting.Posting, conf config.Retirem
 	}
 
 	target := yearlyExpenses.Div(decimal.NewFromFloat(conf.SWR)).Mul(decimal.NewFromFloat(100))
+	now := utils.Now()
 
 	return GoalSummary{
 		Type:     "retirement",
 		Id:       "retirement-" + conf.Name,
 		Name:     conf.Name,
-		Current:  savingsTotal,
-		Target:   target,
+		Current:  deflator.Deflate(savingsTotal, now),
+		Target:   deflator.Deflate(target, now),
 		Icon:     conf.Icon,
 		Priority: conf.Priority,
 	}
@@ -43,7 +53,7 @@ func calculateAverageExpense(db *gorm.DB, conf config.RetirementGoal) decimal.De
 	return utils.SumBy(expenses, func(p posting.Posting) decimal.Decimal { return p.Amount }).Div(decimal.NewFromInt(2))
 }
 
-func getRetirementDetail(db *gorm.DB, conf config.RetirementGoal) gin.H {
+func getRetirementDetail(db *gorm.DB, conf config.RetirementGoal, deflator *service.Deflator) gin.H {
 	savings := accounting.FilterByGlob(query.Init(db).Like("Assets:%").All(), conf.Savings)
 	savings = service.PopulateMarketPrice(db, savings)
 	savingsWithCapitalGains := accounting.FilterByGlob(query.Init(db).Like("Assets:%", "Income:CapitalGains:%").All(), conf.Savings)
@@ -58,19 +68,261 @@ func getRetirementDetail(db *gorm.DB, conf config.RetirementGoal) gin.H {
 	}
 
 	balances := assets.ComputeBreakdowns(db, savingsWithCapitalGains, false)
+	now := utils.Now()
 
-	return gin.H{
+	detail := gin.H{
 		"type":            "retirement",
 		"name":            conf.Name,
 		"icon":            conf.Icon,
-		"savingsTimeline": accounting.RunningBalance(db, savings),
-		"savingsTotal":    savingsTotal,
-		"investmentTotal": investmentTotal,
-		"gainTotal":       gainsTotal,
+		"savingsTimeline": deflatePoints(accounting.RunningBalance(db, savings), deflator),
+		"savingsTotal":    deflator.Deflate(savingsTotal, now),
+		"investmentTotal": deflator.Deflate(investmentTotal, now),
+		"gainTotal":       deflator.Deflate(gainsTotal, now),
 		"swr":             conf.SWR,
-		"yearlyExpense":   yearlyExpenses,
+		"yearlyExpense":   deflator.Deflate(yearlyExpenses, now),
 		"xirr":            service.XIRR(db, savingsWithCapitalGains),
 		"postings":        savingsWithCapitalGains,
 		"balances":        balances,
 	}
+	if deflator != nil {
+		detail["realXirr"] = deflator.XIRR(db, savingsWithCapitalGains)
+	}
+	return detail
+}
+
+const (
+	defaultInflation          = 6.0
+	defaultReturn             = 8.0
+	defaultVolatility         = 10.0
+	defaultRetirementYears    = 30
+	defaultSimulations        = 1000
+	maxSimulations            = 10000
+	maxYears                  = 100
+	maxInflation              = 100.0
+	defaultContributionMonths = 12
+	// returnHistoryMonths is the number of months of history used to
+	// estimate the return and volatility of an asset class
+	returnHistoryMonths = 60
+)
+
+// AssetClassAssumption overrides the estimated return or volatility of
+// an asset class
+type AssetClassAssumption struct {
+	Name       string   `json:"name"`
+	Return     *float64 `json:"return"`
+	Volatility *float64 `json:"volatility"`
+}
+
+type SimulationRequest struct {
+	Inflation          *float64               `json:"inflation"`
+	YearlyContribution *float64               `json:"yearly_contribution"`
+	ContributionMonths int                    `json:"contribution_months"`
+	YearsToRetirement  int                    `json:"years_to_retirement"`
+	RetirementYears    int                    `json:"retirement_years"`
+	Simulations        int                    `json:"simulations"`
+	Seed               int64                  `json:"seed"`
+	AssetClasses       []AssetClassAssumption `json:"asset_classes"`
+}
+
+func GetRetirementSimulation(db *gorm.DB, name string, request SimulationRequest) (gin.H, error) {
+	conf, found := lo.Find(config.GetConfig().Goals.Retirement, func(conf config.RetirementGoal) bool { return conf.Name == name })
+	if !found {
+		return nil, errors.New(fmt.Sprintf("Retirement goal %s not found", name))
+	}
+
+	if request.YearsToRetirement < 0 || request.RetirementYears < 0 || request.ContributionMonths < 0 || request.Simulations < 0 {
+		return nil, errors.New("Years, months and simulations should not be negative")
+	}
+
+	if request.YearsToRetirement > maxYears || request.RetirementYears > maxYears {
+		return nil, errors.New(fmt.Sprintf("Years to retirement and retirement years should not be more than %d", maxYears))
+	}
+
+	if request.Inflation != nil && (*request.Inflation < 0 || *request.Inflation > maxInflation) {
+		return nil, errors.New(fmt.Sprintf("Inflation should be between 0 and %.0f", maxInflation))
+	}
+
+	now := utils.Now()
+	savings := accounting.FilterByGlob(query.Init(db).Like("Assets:%").All(), conf.Savings)
+	savings = service.PopulateMarketPrice(db, savings)
+
+	yearlyExpenses := decimal.NewFromFloat(conf.YearlyExpenses)
+	if !(yearlyExpenses.GreaterThan(decimal.Zero)) {
+		yearlyExpenses = calculateAverageExpense(db, conf)
+	}
+
+	input := SimulationInput{
+		Corpus:            accounting.CurrentBalance(savings).InexactFloat64(),
+		YearlyExpenses:    yearlyExpenses.InexactFloat64(),
+		Inflation:         defaultInflation,
+		YearsToRetirement: request.YearsToRetirement,
+		RetirementYears:   lo.Ternary(request.RetirementYears > 0, request.RetirementYears, defaultRetirementYears),
+		Simulations:       min(lo.Ternary(request.Simulations > 0, request.Simulations, defaultSimulations), maxSimulations),
+		Seed:              request.Seed,
+		AssetClasses:      EstimateAssetClasses(db, savings, now),
+	}
+
+	if request.Inflation != nil {
+		input.Inflation = *request.Inflation
+	}
+
+	if request.YearlyContribution != nil {
+		input.YearlyContribution = *request.YearlyContribution
+	} else {
+		months := lo.Ternary(request.ContributionMonths > 0, request.ContributionMonths, defaultContributionMonths)
+		input.YearlyContribution = yearlyContribution(savings, months, now)
+	}
+
+	for i, class := range input.AssetClasses {
+		assumption, ok := lo.Find(request.AssetClasses, func(a AssetClassAssumption) bool { return a.Name == class.Name })
+		if !ok {
+			continue
+		}
+
+		if assumption.Return != nil {
+			input.AssetClasses[i].Return = *assumption.Return
+		}
+
+		if assumption.Volatility != nil {
+			input.AssetClasses[i].Volatility = *assumption.Volatility
+		}
+	}
+
+	for _, class := range input.AssetClasses {
+		if class.Return <= -100 || class.Volatility < 0 {
+			return nil, errors.New(fmt.Sprintf("Invalid return or volatility for %s", class.Name))
+		}
+	}
+
+	return gin.H{"input": input, "result": simulateRetirement(input, now.Year())}, nil
+}
+
+// yearlyContribution annualizes the net amount invested in the savings
+// accounts during the last n months
+func yearlyContribution(savings []posting.Posting, months int, now time.Time) float64 {
+	start := utils.BeginningOfMonth(now).AddDate(0, -months, 0)
+	end := utils.BeginningOfMonth(now)
+	invested := utils.SumBy(lo.Filter(savings, func(p posting.Posting, _ int) bool {
+		return !p.Date.Before(start) && p.Date.Before(end)
+	}), func(p posting.Posting) decimal.Decimal { return p.Amount })
+	return invested.InexactFloat64() * 12 / float64(months)
+}
+
+// AssetClassOf returns the allocation target of the account, or the
+// second component of the account name (Equity for Assets:Equity:NIFTY)
+// if the allocation targets are not configured
+func AssetClassOf(account string) string {
+	for _, target := range config.GetConfig().AllocationTargets {
+		for _, targetAccount := range target.Accounts {
+			if match, _ := filepath.Match(targetAccount, account); match {
+				return target.Name
+			}
+		}
+	}
+
+	parts := strings.Split(account, ":")
+	if len(parts) > 1 {
+		return parts[1]
+	}
+	return account
+}
+
+// EstimateAssetClasses returns the asset classes of the postings
+// weighted by the balance, with the return and volatility of the last
+// few years
+func EstimateAssetClasses(db *gorm.DB, savings []posting.Posting, now time.Time) []AssetClass {
+	byClass := lo.GroupBy(savings, func(p posting.Posting) string { return AssetClassOf(p.Account) })
+	names := lo.Keys(byClass)
+	sort.Strings(names)
+
+	classes := lo.FilterMap(names, func(name string, _ int) (AssetClass, bool) {
+		postings := byClass[name]
+		balance := accounting.CurrentBalance(postings).InexactFloat64()
+		if balance <= 0 {
+			return AssetClass{}, false
+		}
+
+		class := AssetClass{Name: name, Weight: balance, Return: defaultReturn, Volatility: defaultVolatility}
+		if returns := monthlyReturns(db, postings, now); len(returns) >= 12 {
+			annualReturn, volatility := annualize(returns)
+			class.Return, class.Volatility = round2(annualReturn), round2(volatility)
+		}
+		return class, true
+	})
+
+	total := lo.SumBy(classes, func(class AssetClass) float64 { return class.Weight })
+	for i := range classes {
+		classes[i].Weight = round2(classes[i].Weight / total * 100)
+	}
+	return classes
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// monthlyReturns computes the Modified Dietz return of the postings for
+// each of the last few months, which excludes the effect of the money
+// invested or withdrawn during the month
+func monthlyReturns(db *gorm.DB, postings []posting.Posting, now time.Time) []float64 {
+	if len(postings) == 0 {
+		return nil
+	}
+
+	start := utils.BeginningOfMonth(postings[0].Date)
+	if earliest := utils.BeginningOfMonth(now).AddDate(0, -returnHistoryMonths, 0); start.Before(earliest) {
+		start = earliest
+	}
+
+	quantities := make(map[string]decimal.Decimal)
+	value := func(date time.Time) float64 {
+		total := decimal.Zero
+		for commodity, quantity := range quantities {
+			total = total.Add(service.GetPrice(db, commodity, quantity, date))
+		}
+		return total.InexactFloat64()
+	}
+
+	i := 0
+	for ; i < len(postings) && postings[i].Date.Before(start); i++ {
+		quantities[postings[i].Commodity] = quantities[postings[i].Commodity].Add(postings[i].Quantity)
+	}
+	previous := value(start.AddDate(0, 0, -1))
+
+	returns := []float64{}
+	for month := start; month.Before(utils.BeginningOfMonth(now)); month = month.AddDate(0, 1, 0) {
+		end := utils.EndOfMonth(month)
+		flow := 0.0
+		for ; i < len(postings) && !postings[i].Date.After(end); i++ {
+			quantities[postings[i].Commodity] = quantities[postings[i].Commodity].Add(postings[i].Quantity)
+			flow += postings[i].Amount.InexactFloat64()
+		}
+
+		current := value(end)
+		if base := previous + flow/2; base > 0 {
+			returns = append(returns, (current-previous-flow)/base)
+		}
+		previous = current
+	}
+	return returns
+}
+
+// annualize returns the compounded annual return and the annualized
+// standard deviation of the monthly returns, in percentage
+func annualize(returns []float64) (float64, float64) {
+	growth, mean := 1.0, 0.0
+	for _, r := range returns {
+		growth *= 1 + r
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	annualReturn := (math.Pow(growth, 12/float64(len(returns))) - 1) * 100
+	return annualReturn, math.Sqrt(variance) * math.Sqrt(12) * 100
 }
@@ -1,34 +1,50 @@
 package goal
 
 import (
+	"math"
+	"time"
+
 	"github.com/ananthakumaran/paisa/internal/accounting"
 	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/ananthakumaran/paisa/internal/model/posting"
 	"github.com/ananthakumaran/paisa/internal/query"
 	"github.com/ananthakumaran/paisa/internal/server/assets"
 	"github.com/ananthakumaran/paisa/internal/service"
+	"github.com/ananthakumaran/paisa/internal/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
-func getSavingsSummary(db *gorm.DB, ps []posting.Posting, conf config.SavingsGoal) GoalSummary {
+// getSavingsSummary takes the asset postings and the same postings
+// along with the capital gains, both with the market price populated
+func getSavingsSummary(db *gorm.DB, ps []posting.Posting, psWithCapitalGains []posting.Posting, conf config.SavingsGoal, deflator *service.Deflator) GoalSummary {
 	savings := accounting.FilterByGlob(ps, conf.Accounts)
 	savingsTotal := accounting.CurrentBalance(savings)
 
+	savingsWithCapitalGains := accounting.FilterByGlob(psWithCapitalGains, conf.Accounts)
+	now := utils.Now()
+	projection := getSavingsProjection(savings, conf, service.XIRR(db, savingsWithCapitalGains), now)
+	targetDate := savingsTargetDate(conf, now)
+
 	return GoalSummary{
 		Type:       "savings",
 		Id:         "savings-" + conf.Name,
 		Name:       conf.Name,
-		Current:    savingsTotal,
-		Target:     decimal.NewFromFloat(conf.Target),
+		Current:    deflator.Deflate(savingsTotal, now),
+		Target:     deflator.Deflate(decimal.NewFromFloat(conf.Target), targetDate),
 		TargetDate: conf.TargetDate,
 		Icon:       conf.Icon,
 		Priority:   conf.Priority,
+
+		Projected:                   deflator.Deflate(projection.Projected, targetDate),
+		RequiredMonthlyContribution: deflator.Deflate(projection.RequiredMonthlyContribution, now),
+		Status:                      projection.Status,
 	}
 }
 
-func getSavingsDetail(db *gorm.DB, conf config.SavingsGoal) gin.H {
+func getSavingsDetail(db *gorm.DB, conf config.SavingsGoal, deflator *service.Deflator) gin.H {
 	savings := accounting.FilterByGlob(query.Init(db).Like("Assets:%").All(), conf.Accounts)
 	savings = service.PopulateMarketPrice(db, savings)
 	savingsTotal := accounting.CurrentBalance(savings)
@@ -38,21 +54,160 @@ func getSavingsDetail(db *gorm.DB, conf config.SavingsGoal) gin.H {
 	savingsWithCapitalGains = service.PopulateMarketPrice(db, savingsWithCapitalGains)
 
 	balances := assets.ComputeBreakdowns(db, savingsWithCapitalGains, false)
+	xirr := service.XIRR(db, savingsWithCapitalGains)
+	now := utils.Now()
+	targetDate := savingsTargetDate(conf, now)
+	projection := getSavingsProjection(savings, conf, xirr, now)
+	projection.Projected = deflator.Deflate(projection.Projected, targetDate)
+	projection.MonthlyContribution = deflator.Deflate(projection.MonthlyContribution, now)
+	projection.RequiredMonthlyContribution = deflator.Deflate(projection.RequiredMonthlyContribution, now)
 
-	return gin.H{
+	detail := gin.H{
 		"type":             "savings",
 		"name":             conf.Name,
 		"icon":             conf.Icon,
-		"investmentTotal":  investmentTotal,
-		"savingsTotal":     savingsTotal,
-		"gainTotal":        savingsTotal.Sub(investmentTotal),
-		"savingsTimeline":  accounting.RunningBalance(db, savings),
-		"target":           decimal.NewFromFloat(conf.Target),
+		"investmentTotal":  deflator.Deflate(investmentTotal, now),
+		"savingsTotal":     deflator.Deflate(savingsTotal, now),
+		"gainTotal":        deflator.Deflate(savingsTotal.Sub(investmentTotal), now),
+		"savingsTimeline":  deflatePoints(accounting.RunningBalance(db, savings), deflator),
+		"target":           deflator.Deflate(decimal.NewFromFloat(conf.Target), targetDate),
 		"targetDate":       conf.TargetDate,
 		"rate":             conf.Rate,
 		"paymentPerPeriod": conf.PaymentPerPeriod,
-		"xirr":             service.XIRR(db, savingsWithCapitalGains),
+		"xirr":             xirr,
 		"postings":         savingsWithCapitalGains,
 		"balances":         balances,
+		"projection":       projection,
+	}
+	if deflator != nil {
+		detail["realXirr"] = deflator.XIRR(db, savingsWithCapitalGains)
+	}
+	return detail
+}
+
+const (
+	OnTrack  = "on-track"
+	AtRisk   = "at-risk"
+	OffTrack = "off-track"
+
+	// a goal is at risk if the projected value falls short of the
+	// target by less than 10%
+	atRiskRatio = 0.9
+	// contributionMonths is the number of past months used to compute
+	// the contribution rate
+	contributionMonths = 12
+)
+
+type SavingsProjection struct {
+	Rate                        decimal.Decimal `json:"rate"`
+	MonthlyContribution         decimal.Decimal `json:"monthlyContribution"`
+	Projected                   decimal.Decimal `json:"projected"`
+	RequiredMonthlyContribution decimal.Decimal `json:"requiredMonthlyContribution"`
+	Status                      string          `json:"status"`
+}
+
+// getSavingsProjection projects the savings to the target date,
+// assuming the savings grow at the XIRR of the goal (or the configured
+// rate if there is not enough history) and the contributions continue
+// at the average of the last 12 months
+func getSavingsProjection(savings []posting.Posting, conf config.SavingsGoal, xirr decimal.Decimal, now time.Time) SavingsProjection {
+	rate := xirr
+	if rate.IsZero() {
+		rate = decimal.NewFromFloat(conf.Rate)
+	}
+
+	current := accounting.CurrentBalance(savings)
+	contribution := monthlyContribution(savings, now)
+	projection := SavingsProjection{Rate: rate, MonthlyContribution: contribution, Projected: current}
+
+	targetDate, err := time.ParseInLocation("2006-01-02", conf.TargetDate, now.Location())
+	if err != nil || conf.Target <= 0 {
+		return projection
+	}
+
+	projected, required := projectSavings(current.InexactFloat64(), conf.Target, monthsBetween(now, targetDate), rate.InexactFloat64(), contribution.InexactFloat64())
+	projection.Projected = decimal.NewFromFloat(projected).Round(2)
+	projection.RequiredMonthlyContribution = decimal.NewFromFloat(required).Round(2)
+	projection.Status = savingsStatus(projected, conf.Target)
+	return projection
+}
+
+// savingsTargetDate returns the target date of the goal, now if the
+// target date is not set
+func savingsTargetDate(conf config.SavingsGoal, now time.Time) time.Time {
+	targetDate, err := time.ParseInLocation("2006-01-02", conf.TargetDate, now.Location())
+	if err != nil {
+		return now
+	}
+	return targetDate
+}
+
+func deflatePoints(points []accounting.Point, deflator *service.Deflator) []accounting.Point {
+	if deflator == nil {
+		return points
+	}
+
+	return lo.Map(points, func(p accounting.Point, _ int) accounting.Point {
+		return accounting.Point{Date: p.Date, Value: deflator.Deflate(p.Value, p.Date)}
+	})
+}
+
+// monthlyContribution is the average net amount invested per month
+// during the last 12 months, or since the first investment if the goal
+// is younger than that
+func monthlyContribution(savings []posting.Posting, now time.Time) decimal.Decimal {
+	if len(savings) == 0 {
+		return decimal.Zero
+	}
+
+	end := utils.BeginningOfMonth(now)
+	start := end.AddDate(0, -contributionMonths, 0)
+	months := contributionMonths
+	if first := utils.BeginningOfMonth(savings[0].Date); first.After(start) {
+		start = first
+		months = max(monthsBetween(start, end), 1)
+	}
+
+	invested := utils.SumBy(lo.Filter(savings, func(p posting.Posting, _ int) bool {
+		return !p.Date.Before(start) && p.Date.Before(end)
+	}), func(p posting.Posting) decimal.Decimal { return p.Amount })
+	return invested.Div(decimal.NewFromInt(int64(months))).Round(2)
+}
+
+func monthsBetween(from time.Time, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	return months
+}
+
+// projectSavings returns the future value of the savings after the
+// given months with the monthly contribution, and the monthly
+// contribution required to reach the target
+func projectSavings(current float64, target float64, months int, annualRate float64, contribution float64) (float64, float64) {
+	if months <= 0 {
+		return current, math.Max(target-current, 0)
+	}
+
+	rate := math.Pow(1+annualRate/100, 1.0/12) - 1
+	n := float64(months)
+	if math.Abs(rate) < 1e-9 {
+		return current + contribution*n, math.Max((target-current)/n, 0)
+	}
+
+	growth := math.Pow(1+rate, n)
+	annuity := (growth - 1) / rate
+	return current*growth + contribution*annuity, math.Max((target-current*growth)/annuity, 0)
+}
+
+func savingsStatus(projected float64, target float64) string {
+	switch {
+	case projected >= target:
+		return OnTrack
+	case projected >= target*atRiskRatio:
+		return AtRisk
+	default:
+		return OffTrack
 	}
 }
@@ -0,0 +1,67 @@
+package goal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+)
+
+func date(value string) time.Time {
+	d, _ := time.Parse("2006-01-02", value)
+	return d
+}
+
+func TestProjectSavings(t *testing.T) {
+	projected, required := projectSavings(1000, 3400, 24, 0, 100)
+	assert.InDelta(t, 3400, projected, 0.0001)
+	assert.InDelta(t, 100, required, 0.0001)
+
+	projected, required = projectSavings(100000, 200000, 60, 12, 0)
+	assert.InDelta(t, 176234.17, projected, 0.01)
+	assert.InDelta(t, 295.81, required, 0.01)
+
+	projected, required = projectSavings(0, 100000, 12, 12, 0)
+	assert.InDelta(t, 0, projected, 0.0001)
+	projected, _ = projectSavings(0, 100000, 12, 12, required)
+	assert.InDelta(t, 100000, projected, 0.01)
+
+	projected, required = projectSavings(500, 1000, 0, 10, 100)
+	assert.Equal(t, 500.0, projected)
+	assert.Equal(t, 500.0, required)
+
+	_, required = projectSavings(5000, 1000, 12, 10, 0)
+	assert.Equal(t, 0.0, required)
+}
+
+func TestSavingsStatus(t *testing.T) {
+	assert.Equal(t, OnTrack, savingsStatus(1000, 1000))
+	assert.Equal(t, AtRisk, savingsStatus(950, 1000))
+	assert.Equal(t, OffTrack, savingsStatus(850, 1000))
+}
+
+func TestMonthsBetween(t *testing.T) {
+	assert.Equal(t, 12, monthsBetween(date("2023-01-15"), date("2024-01-15")))
+	assert.Equal(t, 11, monthsBetween(date("2023-01-15"), date("2024-01-14")))
+	assert.Equal(t, -1, monthsBetween(date("2023-02-01"), date("2023-01-01")))
+}
+
+func TestMonthlyContribution(t *testing.T) {
+	investment := func(on string, amount float64) posting.Posting {
+		return posting.Posting{Date: date(on), Account: "Assets:Equity:NIFTY", Amount: decimal.NewFromFloat(amount)}
+	}
+
+	savings := []posting.Posting{
+		investment("2022-06-10", 5000),
+		investment("2023-03-10", 1000),
+		investment("2023-09-10", 2000),
+		investment("2024-01-05", 3000),
+		investment("2024-02-05", 4000),
+	}
+	assert.Equal(t, "500", monthlyContribution(savings, date("2024-02-20")).String())
+	assert.Equal(t, "500", monthlyContribution(savings[2:], date("2024-01-20")).String())
+	assert.True(t, monthlyContribution(nil, date("2024-01-20")).IsZero())
+}
@@ -0,0 +1,167 @@
+package goal
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+type AssetClass struct {
+	Name string `json:"name"`
+	// Weight is the share of the class in the corpus, in percentage
+	Weight float64 `json:"weight"`
+	// Return and Volatility are the expected annual return and its
+	// standard deviation, in percentage
+	Return     float64 `json:"return"`
+	Volatility float64 `json:"volatility"`
+}
+
+type SimulationInput struct {
+	Corpus             float64      `json:"corpus"`
+	YearlyExpenses     float64      `json:"yearly_expenses"`
+	YearlyContribution float64      `json:"yearly_contribution"`
+	Inflation          float64      `json:"inflation"`
+	YearsToRetirement  int          `json:"years_to_retirement"`
+	RetirementYears    int          `json:"retirement_years"`
+	Simulations        int          `json:"simulations"`
+	Seed               int64        `json:"seed"`
+	AssetClasses       []AssetClass `json:"asset_classes"`
+}
+
+// CorpusBand is the distribution of the corpus across the simulations
+// at the end of the year, in today's money
+type CorpusBand struct {
+	Year int     `json:"year"`
+	P10  float64 `json:"p10"`
+	P25  float64 `json:"p25"`
+	P50  float64 `json:"p50"`
+	P75  float64 `json:"p75"`
+	P90  float64 `json:"p90"`
+}
+
+// Depletion is the distribution of the year in which the corpus runs
+// out, among the simulations where it does
+type Depletion struct {
+	Probability float64 `json:"probability"`
+	P10         int     `json:"p10"`
+	P50         int     `json:"p50"`
+	P90         int     `json:"p90"`
+}
+
+type SimulationResult struct {
+	SuccessProbability float64      `json:"success_probability"`
+	Bands              []CorpusBand `json:"bands"`
+	Depletion          Depletion    `json:"depletion"`
+}
+
+// simulateRetirement runs a Monte Carlo simulation of the corpus, year
+// by year. The corpus is rebalanced to the asset class weights every
+// year and each class returns a log normal random return. The
+// contributions until the retirement and the expenses after it grow
+// with the inflation. A simulation succeeds if the corpus lasts till
+// the end of the retirement years.
+func simulateRetirement(input SimulationInput, startYear int) SimulationResult {
+	years := input.YearsToRetirement + input.RetirementYears
+	rng := rand.New(rand.NewSource(input.Seed))
+
+	totalWeight := 0.0
+	for _, class := range input.AssetClasses {
+		totalWeight += class.Weight
+	}
+
+	type logNormal struct{ weight, mu, sigma float64 }
+	classes := make([]logNormal, 0, len(input.AssetClasses))
+	for _, class := range input.AssetClasses {
+		weight := 1 / float64(len(input.AssetClasses))
+		if totalWeight > 0 {
+			weight = class.Weight / totalWeight
+		}
+
+		mean, sd := 1+class.Return/100, class.Volatility/100
+		sigma2 := math.Log(1 + (sd*sd)/(mean*mean))
+		classes = append(classes, logNormal{weight: weight, mu: math.Log(mean) - sigma2/2, sigma: math.Sqrt(sigma2)})
+	}
+
+	inflation := 1 + input.Inflation/100
+	corpusByYear := make([][]float64, years)
+	for i := range corpusByYear {
+		corpusByYear[i] = make([]float64, input.Simulations)
+	}
+
+	successes := 0
+	depletions := []int{}
+	for s := 0; s < input.Simulations; s++ {
+		corpus := input.Corpus
+		depleted := false
+		for year := 0; year < years; year++ {
+			growth := 0.0
+			for _, class := range classes {
+				growth += class.weight * math.Exp(class.mu+class.sigma*rng.NormFloat64())
+			}
+			if len(classes) == 0 {
+				growth = 1
+			}
+
+			price := math.Pow(inflation, float64(year))
+			corpus *= growth
+			if year < input.YearsToRetirement {
+				corpus += input.YearlyContribution * price
+			} else {
+				corpus -= input.YearlyExpenses * price
+			}
+
+			if corpus <= 0 && !depleted {
+				depleted = true
+				depletions = append(depletions, startYear+year)
+			}
+
+			if depleted {
+				corpus = 0
+			}
+
+			corpusByYear[year][s] = corpus / (price * inflation)
+		}
+
+		if !depleted {
+			successes++
+		}
+	}
+
+	result := SimulationResult{Bands: make([]CorpusBand, 0, years)}
+	if input.Simulations == 0 {
+		return result
+	}
+
+	result.SuccessProbability = float64(successes) / float64(input.Simulations) * 100
+	for year, corpus := range corpusByYear {
+		sort.Float64s(corpus)
+		result.Bands = append(result.Bands, CorpusBand{
+			Year: startYear + year,
+			P10:  percentile(corpus, 10),
+			P25:  percentile(corpus, 25),
+			P50:  percentile(corpus, 50),
+			P75:  percentile(corpus, 75),
+			P90:  percentile(corpus, 90),
+		})
+	}
+
+	if len(depletions) > 0 {
+		sort.Ints(depletions)
+		result.Depletion = Depletion{
+			Probability: float64(len(depletions)) / float64(input.Simulations) * 100,
+			P10:         depletions[percentileIndex(len(depletions), 10)],
+			P50:         depletions[percentileIndex(len(depletions), 50)],
+			P90:         depletions[percentileIndex(len(depletions), 90)],
+		}
+	}
+
+	return result
+}
+
+func percentileIndex(n int, p float64) int {
+	return min(int(math.Round(p/100*float64(n-1))), n-1)
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	return sorted[percentileIndex(len(sorted), p)]
+}
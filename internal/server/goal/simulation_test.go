@@ -0,0 +1,81 @@
+package goal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateRetirementWithoutVolatility(t *testing.T) {
+	input := SimulationInput{
+		Corpus:            1000,
+		YearlyExpenses:    100,
+		RetirementYears:   15,
+		Simulations:       10,
+		AssetClasses:      []AssetClass{{Name: "Debt", Weight: 100, Return: 0, Volatility: 0}},
+		YearsToRetirement: 0,
+	}
+
+	result := simulateRetirement(input, 2024)
+	assert.Equal(t, 0.0, result.SuccessProbability)
+	assert.Equal(t, 100.0, result.Depletion.Probability)
+	assert.Equal(t, 2033, result.Depletion.P50)
+	assert.Len(t, result.Bands, 15)
+	assert.Equal(t, CorpusBand{Year: 2024, P10: 900, P25: 900, P50: 900, P75: 900, P90: 900}, result.Bands[0])
+
+	input.YearlyContribution = 100
+	input.YearsToRetirement = 5
+	input.RetirementYears = 14
+	result = simulateRetirement(input, 2024)
+	assert.Equal(t, 100.0, result.SuccessProbability)
+	assert.Equal(t, 0.0, result.Depletion.Probability)
+	assert.InDelta(t, 1500, result.Bands[4].P50, 0.0001)
+	assert.InDelta(t, 100, result.Bands[18].P50, 0.0001)
+}
+
+func TestSimulateRetirementInflation(t *testing.T) {
+	input := SimulationInput{
+		Corpus:          1000,
+		YearlyExpenses:  0,
+		Inflation:       10,
+		RetirementYears: 1,
+		Simulations:     1,
+		AssetClasses:    []AssetClass{{Name: "Equity", Weight: 100, Return: 10, Volatility: 0}},
+	}
+
+	result := simulateRetirement(input, 2024)
+	assert.InDelta(t, 1000, result.Bands[0].P50, 0.0001)
+}
+
+func TestSimulateRetirementIsReproducible(t *testing.T) {
+	input := SimulationInput{
+		Corpus:          3000,
+		YearlyExpenses:  100,
+		Inflation:       5,
+		RetirementYears: 30,
+		Simulations:     500,
+		Seed:            7,
+		AssetClasses: []AssetClass{
+			{Name: "Equity", Weight: 70, Return: 11, Volatility: 18},
+			{Name: "Debt", Weight: 30, Return: 7, Volatility: 3},
+		},
+	}
+
+	first := simulateRetirement(input, 2024)
+	second := simulateRetirement(input, 2024)
+	assert.Equal(t, first, second)
+	assert.Greater(t, first.SuccessProbability, 0.0)
+	assert.Less(t, first.SuccessProbability, 100.0)
+	assert.InDelta(t, 100, first.SuccessProbability+first.Depletion.Probability, 0.0001)
+	for _, band := range first.Bands {
+		assert.LessOrEqual(t, band.P10, band.P50)
+		assert.LessOrEqual(t, band.P50, band.P90)
+	}
+}
+
+func TestAnnualize(t *testing.T) {
+	returns := []float64{0.01, 0.01, 0.01, 0.01, 0.01, 0.01, 0.01, 0.01, 0.01, 0.01, 0.01, 0.01}
+	annualReturn, volatility := annualize(returns)
+	assert.InDelta(t, 12.6825, annualReturn, 0.0001)
+	assert.InDelta(t, 0, volatility, 0.0001)
+}
@@ -33,9 +33,13 @@ type RunningBalance struct {
 	quantity map[string]decimal.Decimal
 }
 
-func GetIncomeStatement(db *gorm.DB) gin.H {
+func GetIncomeStatement(db *gorm.DB, deflator *service.Deflator) gin.H {
 	postings := query.Init(db).All()
 	statements := computeStatement(db, postings)
+	for fy, statement := range statements {
+		_, end := utils.ParseFY(fy)
+		statements[fy] = deflateStatement(statement, end, deflator)
+	}
 	return gin.H{"yearly": statements}
 }
 
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/service"
+	"github.com/ananthakumaran/paisa/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// deflatorOf returns the deflator if the amounts are requested after
+// adjusting for the inflation with real=true, in the money of the base
+// date (today by default). The deflator is nil otherwise.
+func deflatorOf(db *gorm.DB, c *gin.Context) (*service.Deflator, error) {
+	if c.Query("real") != "true" {
+		return nil, nil
+	}
+
+	base := utils.EndOfToday()
+	if value := c.Query("base"); value != "" {
+		date, err := time.ParseInLocation("2006-01-02", value, config.TimeZone())
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid base date %s, expected YYYY-MM-DD", value))
+		}
+		base = date
+	}
+
+	return service.NewDeflator(db, base)
+}
+
+// deflateNetworths deflates the balance at the date of the point. The
+// investment and the withdrawal are cumulative, the change since the
+// previous point is deflated at the date of the point and added up.
+func deflateNetworths(networths []Networth, deflator *service.Deflator) []Networth {
+	if deflator == nil {
+		return networths
+	}
+
+	previous := Networth{}
+	investment, withdrawal := decimal.Zero, decimal.Zero
+	deflated := make([]Networth, len(networths))
+	for i, n := range networths {
+		factor := deflator.Factor(n.Date)
+		investment = investment.Add(n.InvestmentAmount.Sub(previous.InvestmentAmount).Mul(factor))
+		withdrawal = withdrawal.Add(n.WithdrawalAmount.Sub(previous.WithdrawalAmount).Mul(factor))
+		previous = n
+
+		n.InvestmentAmount = investment
+		n.WithdrawalAmount = withdrawal
+		n.BalanceAmount = n.BalanceAmount.Mul(factor)
+		n.GainAmount = n.BalanceAmount.Add(withdrawal).Sub(investment)
+		n.NetInvestmentAmount = investment.Sub(withdrawal)
+		deflated[i] = n
+	}
+	return deflated
+}
+
+// deflateStatement deflates all the amounts of the year with the index
+// at the end of the year, the starting balance includes the loss of
+// value during the year
+func deflateStatement(statement IncomeStatement, end time.Time, deflator *service.Deflator) IncomeStatement {
+	if deflator == nil {
+		return statement
+	}
+
+	factor := deflator.Factor(end)
+	deflate := func(breakdown map[string]decimal.Decimal) map[string]decimal.Decimal {
+		deflated := make(map[string]decimal.Decimal, len(breakdown))
+		for account, amount := range breakdown {
+			deflated[account] = amount.Mul(factor)
+		}
+		return deflated
+	}
+
+	statement.StartingBalance = statement.StartingBalance.Mul(factor)
+	statement.EndingBalance = statement.EndingBalance.Mul(factor)
+	statement.Income = deflate(statement.Income)
+	statement.Interest = deflate(statement.Interest)
+	statement.Equity = deflate(statement.Equity)
+	statement.Pnl = deflate(statement.Pnl)
+	statement.Liabilities = deflate(statement.Liabilities)
+	statement.Tax = deflate(statement.Tax)
+	statement.Expenses = deflate(statement.Expenses)
+	return statement
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/model/cpi"
+	"github.com/ananthakumaran/paisa/internal/service"
+)
+
+func TestDeflateNetworths(t *testing.T) {
+	deflator, err := service.NewDeflatorFromCPI([]cpi.CPI{
+		{Year: 2020, Index: decimal.NewFromInt(100)},
+		{Year: 2021, Index: decimal.NewFromInt(110)},
+	}, time.Date(2020, 7, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	networths := []Networth{
+		{Date: time.Date(2020, 7, 2, 0, 0, 0, 0, time.UTC), InvestmentAmount: decimal.NewFromInt(1000), BalanceAmount: decimal.NewFromInt(1000)},
+		{Date: time.Date(2021, 7, 2, 0, 0, 0, 0, time.UTC), InvestmentAmount: decimal.NewFromInt(2100), WithdrawalAmount: decimal.NewFromInt(110), BalanceAmount: decimal.NewFromInt(2200)},
+	}
+
+	deflated := deflateNetworths(networths, deflator)
+	assert.InDelta(t, 1000, deflated[0].InvestmentAmount.InexactFloat64(), 0.5)
+
+	// the investment of the second year is deflated at its own date
+	assert.InDelta(t, 2000, deflated[1].InvestmentAmount.InexactFloat64(), 0.5)
+	assert.InDelta(t, 100, deflated[1].WithdrawalAmount.InexactFloat64(), 0.5)
+	assert.InDelta(t, 2000, deflated[1].BalanceAmount.InexactFloat64(), 0.5)
+	assert.InDelta(t, 100, deflated[1].GainAmount.InexactFloat64(), 0.5)
+	assert.InDelta(t, 1900, deflated[1].NetInvestmentAmount.InexactFloat64(), 0.5)
+
+	assert.Equal(t, networths, deflateNetworths(networths, nil))
+}
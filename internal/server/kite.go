@@ -0,0 +1,28 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/background/kite"
+)
+
+func GetKiteAccounts(db *gorm.DB) gin.H {
+	accounts, err := kite.GetAccountStatuses(db)
+	if err != nil {
+		log.Warn(err)
+		return gin.H{"accounts": []kite.AccountStatus{}, "error": err.Error()}
+	}
+
+	return gin.H{"accounts": accounts}
+}
+
+func LoginKiteAccount(name string) gin.H {
+	loginURL, err := kite.GetLoginURL(name)
+	if err != nil {
+		return gin.H{"success": false, "message": err.Error()}
+	}
+
+	return gin.H{"success": true, "login_url": loginURL}
+}
@@ -0,0 +1,115 @@
+package liabilities
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/accounting"
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/loan"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/query"
+	"github.com/ananthakumaran/paisa/internal/utils"
+)
+
+const (
+	Matched  = "matched"
+	Mismatch = "mismatch"
+	Missing  = "missing"
+	Upcoming = "upcoming"
+)
+
+// installmentTolerance allows for the rounding differences between the
+// schedule and the lender
+var installmentTolerance = decimal.NewFromInt(1)
+
+type LoanInstallment struct {
+	loan.Installment
+	ActualPrincipal decimal.Decimal `json:"actual_principal"`
+	ActualInterest  decimal.Decimal `json:"actual_interest"`
+	Status          string          `json:"status"`
+}
+
+type LoanSchedule struct {
+	Name             string            `json:"name"`
+	Account          string            `json:"account"`
+	InterestAccount  string            `json:"interest_account"`
+	Balance          decimal.Decimal   `json:"balance"`
+	ScheduledBalance decimal.Decimal   `json:"scheduled_balance"`
+	Installments     []LoanInstallment `json:"installments"`
+	Error            string            `json:"error"`
+}
+
+func GetLoans(db *gorm.DB) gin.H {
+	return gin.H{"loans": LoanSchedules(db)}
+}
+
+// LoanSchedules returns the schedule of the configured loans along with
+// the principal and interest actually recorded in the journal for every
+// installment
+func LoanSchedules(db *gorm.DB) []LoanSchedule {
+	now := utils.Now()
+	schedules := []LoanSchedule{}
+	for _, conf := range config.GetConfig().Loans {
+		postings := query.Init(db).Where("account = ?", conf.Account).All()
+		interests := query.Init(db).Where("account = ?", loan.InterestAccount(conf)).All()
+		schedule := LoanSchedule{
+			Name:             conf.Name,
+			Account:          conf.Account,
+			InterestAccount:  loan.InterestAccount(conf),
+			Balance:          accounting.CostSum(postings).Neg(),
+			ScheduledBalance: decimal.NewFromFloat(conf.Principal),
+			Installments:     []LoanInstallment{},
+		}
+
+		installments, err := loan.Schedule(conf)
+		if err != nil {
+			schedule.Error = err.Error()
+			schedules = append(schedules, schedule)
+			continue
+		}
+
+		for _, installment := range installments {
+			if !installment.Date.After(now) {
+				schedule.ScheduledBalance = installment.Balance
+			}
+		}
+
+		schedule.Installments = checkSchedule(installments, postings, interests, now)
+		schedules = append(schedules, schedule)
+	}
+	return schedules
+}
+
+// checkSchedule compares the installments with the repayments and the
+// interest recorded in the same month
+func checkSchedule(installments []loan.Installment, postings []posting.Posting, interests []posting.Posting, now time.Time) []LoanInstallment {
+	repayments := utils.GroupByMonth(lo.Filter(postings, func(p posting.Posting, _ int) bool { return p.Amount.IsPositive() }))
+	interestsByMonth := utils.GroupByMonth(interests)
+
+	return lo.Map(installments, func(installment loan.Installment, _ int) LoanInstallment {
+		month := installment.Date.Format("2006-01")
+		checked := LoanInstallment{
+			Installment:     installment,
+			ActualPrincipal: accounting.CostSum(repayments[month]),
+			ActualInterest:  accounting.CostSum(interestsByMonth[month]),
+		}
+
+		switch {
+		case installment.Date.After(now):
+			checked.Status = Upcoming
+		case checked.ActualPrincipal.IsZero() && checked.ActualInterest.IsZero():
+			checked.Status = Missing
+		case checked.ActualPrincipal.Sub(installment.Principal).Abs().GreaterThan(installmentTolerance) ||
+			checked.ActualInterest.Sub(installment.Interest).Abs().GreaterThan(installmentTolerance):
+			checked.Status = Mismatch
+		default:
+			checked.Status = Matched
+		}
+		return checked
+	})
+}
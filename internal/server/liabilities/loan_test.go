@@ -0,0 +1,40 @@
+package liabilities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/loan"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+)
+
+func TestCheckSchedule(t *testing.T) {
+	installments := []loan.Installment{
+		{Date: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), Principal: d(900), Interest: d(100)},
+		{Date: time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC), Principal: d(909), Interest: d(91)},
+		{Date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), Principal: d(918.09), Interest: d(81.91)},
+		{Date: time.Date(2024, 4, 5, 0, 0, 0, 0, time.UTC), Principal: d(927.27), Interest: d(72.73)},
+	}
+
+	entry := func(date time.Time, account string, amount float64) posting.Posting {
+		return posting.Posting{Date: date, Account: account, Amount: d(amount)}
+	}
+	postings := []posting.Posting{
+		entry(time.Date(2023, 12, 5, 0, 0, 0, 0, time.UTC), "Liabilities:Home", -10000),
+		entry(time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), "Liabilities:Home", 900.4),
+		entry(time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC), "Liabilities:Home", 1000),
+	}
+	interests := []posting.Posting{
+		entry(time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), "Expenses:Interest:Home", 100),
+	}
+
+	checked := checkSchedule(installments, postings, interests, time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, Matched, checked[0].Status)
+	assert.Equal(t, Mismatch, checked[1].Status)
+	assert.Equal(t, "1000", checked[1].ActualPrincipal.String())
+	assert.True(t, checked[1].ActualInterest.IsZero())
+	assert.Equal(t, Missing, checked[2].Status)
+	assert.Equal(t, Upcoming, checked[3].Status)
+}
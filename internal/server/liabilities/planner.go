@@ -0,0 +1,340 @@
+package liabilities
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/accounting"
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/loan"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/query"
+	"github.com/ananthakumaran/paisa/internal/service"
+	"github.com/ananthakumaran/paisa/internal/utils"
+)
+
+const (
+	Minimum   = "minimum"
+	Avalanche = "avalanche"
+	Snowball  = "snowball"
+	Custom    = "custom"
+
+	// emiMonths is the number of past months used to compute the EMI
+	emiMonths = 3
+	// maxPlanMonths stops the plan of the loans which are never paid off
+	// because the EMI doesn't cover the interest
+	maxPlanMonths = 600
+)
+
+type Loan struct {
+	Account string          `json:"account"`
+	APR     decimal.Decimal `json:"apr"`
+	Balance decimal.Decimal `json:"balance"`
+	EMI     decimal.Decimal `json:"emi"`
+}
+
+// Prepayment is paid towards the account on the month, and every month
+// after that if recurring
+type Prepayment struct {
+	Account   string          `json:"account"`
+	Month     string          `json:"month"`
+	Amount    decimal.Decimal `json:"amount"`
+	Recurring bool            `json:"recurring"`
+}
+
+type PlannerRequest struct {
+	// Loans overrides the APR, balance or EMI computed from the journal,
+	// zero values are ignored
+	Loans []Loan `json:"loans"`
+	// ExtraPayment is paid every month on top of the EMIs in the
+	// avalanche and snowball strategies
+	ExtraPayment decimal.Decimal `json:"extra_payment"`
+	Prepayments  []Prepayment    `json:"prepayments"`
+}
+
+type ScheduleEntry struct {
+	Date      time.Time       `json:"date"`
+	Payment   decimal.Decimal `json:"payment"`
+	Interest  decimal.Decimal `json:"interest"`
+	Principal decimal.Decimal `json:"principal"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+type LoanPlan struct {
+	Account       string          `json:"account"`
+	TotalInterest decimal.Decimal `json:"total_interest"`
+	PayoffDate    *time.Time      `json:"payoff_date"`
+	Schedule      []ScheduleEntry `json:"schedule"`
+}
+
+type Plan struct {
+	Strategy      string          `json:"strategy"`
+	TotalInterest decimal.Decimal `json:"total_interest"`
+	InterestSaved decimal.Decimal `json:"interest_saved"`
+	PayoffDate    *time.Time      `json:"payoff_date"`
+	Loans         []LoanPlan      `json:"loans"`
+}
+
+func GetPlanner(db *gorm.DB, request PlannerRequest) (gin.H, error) {
+	if err := validatePlannerRequest(request); err != nil {
+		return nil, err
+	}
+
+	loans := currentLoans(db, utils.Now())
+	for i, loan := range loans {
+		override, ok := lo.Find(request.Loans, func(l Loan) bool { return l.Account == loan.Account })
+		if !ok {
+			continue
+		}
+
+		if !override.APR.IsZero() {
+			loans[i].APR = override.APR
+		}
+		if !override.Balance.IsZero() {
+			loans[i].Balance = override.Balance
+		}
+		if !override.EMI.IsZero() {
+			loans[i].EMI = override.EMI
+		}
+	}
+
+	start := utils.BeginningOfMonth(utils.Now()).AddDate(0, 1, 0)
+	minimum := planPayoff(loans, Minimum, decimal.Zero, nil, start)
+	plans := []Plan{minimum}
+	for _, strategy := range []string{Avalanche, Snowball, Custom} {
+		plan := planPayoff(loans, strategy, request.ExtraPayment, request.Prepayments, start)
+		plan.InterestSaved = minimum.TotalInterest.Sub(plan.TotalInterest)
+		plans = append(plans, plan)
+	}
+
+	return gin.H{"loans": loans, "plans": plans}, nil
+}
+
+func validatePlannerRequest(request PlannerRequest) error {
+	if request.ExtraPayment.IsNegative() {
+		return errors.New("Extra payment should not be negative")
+	}
+
+	for _, prepayment := range request.Prepayments {
+		if _, err := time.Parse("2006-01", prepayment.Month); err != nil {
+			return errors.New(fmt.Sprintf("Invalid prepayment month %s, expected YYYY-MM", prepayment.Month))
+		}
+		if prepayment.Amount.IsNegative() {
+			return errors.New(fmt.Sprintf("Prepayment for %s should not be negative", prepayment.Account))
+		}
+	}
+
+	for _, loan := range request.Loans {
+		if loan.APR.IsNegative() || loan.Balance.IsNegative() || loan.EMI.IsNegative() {
+			return errors.New(fmt.Sprintf("APR, balance and EMI of %s should not be negative", loan.Account))
+		}
+	}
+
+	return nil
+}
+
+// currentLoans returns the outstanding liabilities with the APR and the
+// average repayment of the last few months as the EMI. The rate and the
+// EMI of the next installment are used for the configured loans.
+func currentLoans(db *gorm.DB, now time.Time) []Loan {
+	postings := query.Init(db).Like("Liabilities:%").All()
+	expenses := query.Init(db).Like("Expenses:Interest:%").All()
+	postings = service.PopulateMarketPrice(db, postings)
+
+	loans := []Loan{}
+	for account, ps := range lo.GroupBy(postings, func(p posting.Posting) string { return p.Account }) {
+		balance := accounting.CurrentBalance(ps).Neg()
+		if !balance.IsPositive() {
+			continue
+		}
+
+		es := lo.Filter(expenses, func(e posting.Posting, _ int) bool { return "Liabilities:"+e.RestName(2) == account })
+		l := Loan{
+			Account: account,
+			APR:     service.APR(db, append(ps, es...)),
+			Balance: balance.Round(2),
+			EMI:     averageRepayment(ps, now),
+		}
+
+		if installment, ok := nextInstallment(account, now); ok {
+			l.APR = installment.Rate
+			l.EMI = installment.EMI
+		}
+		loans = append(loans, l)
+	}
+
+	sort.Slice(loans, func(i, j int) bool { return loans[i].Account < loans[j].Account })
+	return loans
+}
+
+// nextInstallment returns the next installment of the account if it is
+// one of the configured loans
+func nextInstallment(account string, now time.Time) (loan.Installment, bool) {
+	conf, ok := lo.Find(config.GetConfig().Loans, func(l config.Loan) bool { return l.Account == account })
+	if !ok {
+		return loan.Installment{}, false
+	}
+
+	installments, err := loan.Schedule(conf)
+	if err != nil {
+		return loan.Installment{}, false
+	}
+
+	return lo.Find(installments, func(i loan.Installment) bool { return i.Date.After(now) })
+}
+
+func averageRepayment(postings []posting.Posting, now time.Time) decimal.Decimal {
+	end := utils.BeginningOfMonth(now)
+	start := end.AddDate(0, -emiMonths, 0)
+	repayments := lo.Filter(postings, func(p posting.Posting, _ int) bool {
+		return p.Amount.IsPositive() && !p.Date.Before(start) && p.Date.Before(end)
+	})
+
+	months := len(lo.Uniq(lo.Map(repayments, func(p posting.Posting, _ int) string { return p.Date.Format("2006-01") })))
+	if months == 0 {
+		return decimal.Zero
+	}
+
+	return accounting.CostSum(repayments).Div(decimal.NewFromInt(int64(months))).Round(2)
+}
+
+// planPayoff simulates the repayment of the loans month by month. The
+// interest is charged monthly on the outstanding balance and the EMI
+// is paid first. In the avalanche and snowball strategies, the extra
+// payment and the EMIs of the loans already paid off go towards the
+// loan with the highest APR or the lowest balance respectively. The
+// custom strategy pays the prepayments on top of the EMIs.
+func planPayoff(loans []Loan, strategy string, extra decimal.Decimal, prepayments []Prepayment, start time.Time) Plan {
+	hundred := decimal.NewFromInt(100)
+	twelve := decimal.NewFromInt(12)
+
+	balances := lo.Map(loans, func(l Loan, _ int) decimal.Decimal { return l.Balance })
+	plans := lo.Map(loans, func(l Loan, _ int) LoanPlan {
+		return LoanPlan{Account: l.Account, TotalInterest: decimal.Zero, Schedule: []ScheduleEntry{}}
+	})
+
+	for month := 0; month < maxPlanMonths; month++ {
+		if lo.EveryBy(balances, func(b decimal.Decimal) bool { return !b.IsPositive() }) {
+			break
+		}
+
+		date := start.AddDate(0, month, 0)
+		active := lo.Map(balances, func(b decimal.Decimal, _ int) bool { return b.IsPositive() })
+		payments := make([]decimal.Decimal, len(loans))
+		interests := make([]decimal.Decimal, len(loans))
+		pool := decimal.Zero
+		if strategy == Avalanche || strategy == Snowball {
+			pool = extra
+		}
+
+		for i, loan := range loans {
+			if !active[i] {
+				if strategy == Avalanche || strategy == Snowball {
+					pool = pool.Add(loan.EMI)
+				}
+				continue
+			}
+
+			interests[i] = balances[i].Mul(loan.APR).Div(hundred).Div(twelve).Round(2)
+			balances[i] = balances[i].Add(interests[i])
+			payments[i] = decimal.Min(loan.EMI, balances[i])
+			balances[i] = balances[i].Sub(payments[i])
+			if strategy == Avalanche || strategy == Snowball {
+				pool = pool.Add(loan.EMI.Sub(payments[i]))
+			}
+		}
+
+		if strategy == Custom {
+			for _, prepayment := range prepayments {
+				if !prepaymentDue(prepayment, date) {
+					continue
+				}
+
+				i := lo.IndexOf(lo.Map(loans, func(l Loan, _ int) string { return l.Account }), prepayment.Account)
+				if i == -1 {
+					continue
+				}
+
+				amount := decimal.Min(prepayment.Amount, balances[i])
+				payments[i] = payments[i].Add(amount)
+				balances[i] = balances[i].Sub(amount)
+			}
+		}
+
+		for _, i := range payoffOrder(loans, balances, strategy) {
+			if !pool.IsPositive() {
+				break
+			}
+
+			amount := decimal.Min(pool, balances[i])
+			payments[i] = payments[i].Add(amount)
+			balances[i] = balances[i].Sub(amount)
+			pool = pool.Sub(amount)
+		}
+
+		for i := range loans {
+			if !active[i] {
+				continue
+			}
+
+			plans[i].TotalInterest = plans[i].TotalInterest.Add(interests[i])
+			plans[i].Schedule = append(plans[i].Schedule, ScheduleEntry{
+				Date:      date,
+				Payment:   payments[i],
+				Interest:  interests[i],
+				Principal: payments[i].Sub(interests[i]),
+				Balance:   balances[i],
+			})
+
+			if !balances[i].IsPositive() && plans[i].PayoffDate == nil {
+				payoff := date
+				plans[i].PayoffDate = &payoff
+			}
+		}
+	}
+
+	plan := Plan{Strategy: strategy, TotalInterest: decimal.Zero, InterestSaved: decimal.Zero, Loans: plans}
+	paidOff := true
+	for i, loanPlan := range plans {
+		plan.TotalInterest = plan.TotalInterest.Add(loanPlan.TotalInterest)
+		if balances[i].IsPositive() {
+			paidOff = false
+		} else if loanPlan.PayoffDate != nil && (plan.PayoffDate == nil || loanPlan.PayoffDate.After(*plan.PayoffDate)) {
+			plan.PayoffDate = loanPlan.PayoffDate
+		}
+	}
+
+	if !paidOff {
+		plan.PayoffDate = nil
+	}
+	return plan
+}
+
+// payoffOrder returns the outstanding loans in the order the extra
+// payment should be applied
+func payoffOrder(loans []Loan, balances []decimal.Decimal, strategy string) []int {
+	order := lo.Filter(lo.Range(len(loans)), func(i int, _ int) bool { return balances[i].IsPositive() })
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if strategy == Snowball {
+			return balances[i].LessThan(balances[j])
+		}
+		return loans[i].APR.GreaterThan(loans[j].APR)
+	})
+	return order
+}
+
+func prepaymentDue(prepayment Prepayment, date time.Time) bool {
+	month := date.Format("2006-01")
+	if prepayment.Recurring {
+		return month >= prepayment.Month
+	}
+	return month == prepayment.Month
+}
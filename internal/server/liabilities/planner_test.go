@@ -0,0 +1,93 @@
+package liabilities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func d(value float64) decimal.Decimal {
+	return decimal.NewFromFloat(value)
+}
+
+var planStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestPlanPayoffMinimum(t *testing.T) {
+	loans := []Loan{{Account: "Liabilities:Car", APR: d(12), Balance: d(1000), EMI: d(510)}}
+	plan := planPayoff(loans, Minimum, decimal.Zero, nil, planStart)
+
+	schedule := plan.Loans[0].Schedule
+	assert.Len(t, schedule, 2)
+	assert.Equal(t, "10", schedule[0].Interest.String())
+	assert.Equal(t, "500", schedule[0].Principal.String())
+	assert.Equal(t, "500", schedule[0].Balance.String())
+	assert.Equal(t, "5", schedule[1].Interest.String())
+	assert.Equal(t, "505", schedule[1].Payment.String())
+	assert.Equal(t, "0", schedule[1].Balance.String())
+	assert.Equal(t, "15", plan.TotalInterest.String())
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), *plan.PayoffDate)
+}
+
+func TestPlanPayoffNeverPaidOff(t *testing.T) {
+	loans := []Loan{{Account: "Liabilities:Card", APR: d(36), Balance: d(1000), EMI: d(20)}}
+	plan := planPayoff(loans, Minimum, decimal.Zero, nil, planStart)
+	assert.Nil(t, plan.PayoffDate)
+	assert.Nil(t, plan.Loans[0].PayoffDate)
+	assert.Len(t, plan.Loans[0].Schedule, maxPlanMonths)
+}
+
+func TestPlanPayoffStrategies(t *testing.T) {
+	loans := []Loan{
+		{Account: "Liabilities:Card", APR: d(36), Balance: d(5000), EMI: d(200)},
+		{Account: "Liabilities:Personal", APR: d(12), Balance: d(2000), EMI: d(200)},
+	}
+
+	minimum := planPayoff(loans, Minimum, decimal.Zero, nil, planStart)
+	avalanche := planPayoff(loans, Avalanche, d(500), nil, planStart)
+	snowball := planPayoff(loans, Snowball, d(500), nil, planStart)
+
+	// the extra payment goes to the card in avalanche and to the
+	// personal loan in snowball
+	assert.Equal(t, "700", avalanche.Loans[0].Schedule[0].Payment.String())
+	assert.Equal(t, "200", avalanche.Loans[1].Schedule[0].Payment.String())
+	assert.Equal(t, "200", snowball.Loans[0].Schedule[0].Payment.String())
+	assert.Equal(t, "700", snowball.Loans[1].Schedule[0].Payment.String())
+
+	assert.True(t, avalanche.TotalInterest.LessThan(snowball.TotalInterest))
+	assert.True(t, snowball.TotalInterest.LessThan(minimum.TotalInterest))
+	assert.True(t, avalanche.PayoffDate.Before(*minimum.PayoffDate))
+	assert.True(t, snowball.Loans[1].PayoffDate.Before(*avalanche.Loans[1].PayoffDate))
+}
+
+func TestPlanPayoffCustom(t *testing.T) {
+	loans := []Loan{{Account: "Liabilities:Home", APR: d(0), Balance: d(1000), EMI: d(100)}}
+	prepayments := []Prepayment{
+		{Account: "Liabilities:Home", Month: "2024-02", Amount: d(300)},
+		{Account: "Liabilities:Home", Month: "2024-03", Amount: d(50), Recurring: true},
+		{Account: "Liabilities:Unknown", Month: "2024-01", Amount: d(1000)},
+	}
+
+	plan := planPayoff(loans, Custom, d(500), prepayments, planStart)
+	payments := []string{}
+	for _, entry := range plan.Loans[0].Schedule {
+		payments = append(payments, entry.Payment.String())
+	}
+	assert.Equal(t, []string{"100", "400", "150", "150", "150", "50"}, payments)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), *plan.PayoffDate)
+}
+
+func TestValidatePlannerRequest(t *testing.T) {
+	assert.NoError(t, validatePlannerRequest(PlannerRequest{
+		Loans:       []Loan{{Account: "Liabilities:Home", APR: d(9)}},
+		Prepayments: []Prepayment{{Account: "Liabilities:Home", Month: "2024-02", Amount: d(300)}},
+	}))
+	assert.Error(t, validatePlannerRequest(PlannerRequest{ExtraPayment: d(-1)}))
+	assert.Error(t, validatePlannerRequest(PlannerRequest{
+		Prepayments: []Prepayment{{Account: "Liabilities:Home", Month: "2024-02", Amount: d(-300)}},
+	}))
+	assert.Error(t, validatePlannerRequest(PlannerRequest{Loans: []Loan{{Account: "Liabilities:Home", APR: d(-1)}}}))
+	assert.Error(t, validatePlannerRequest(PlannerRequest{Loans: []Loan{{Account: "Liabilities:Home", Balance: d(-1)}}}))
+	assert.Error(t, validatePlannerRequest(PlannerRequest{Loans: []Loan{{Account: "Liabilities:Home", EMI: d(-1)}}}))
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"time"
 
+	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/ananthakumaran/paisa/internal/model/posting"
 	"github.com/ananthakumaran/paisa/internal/query"
 	"github.com/ananthakumaran/paisa/internal/service"
@@ -22,13 +23,44 @@ type Networth struct {
 	NetInvestmentAmount decimal.Decimal `json:"netInvestmentAmount"`
 }
 
-func GetNetworth(db *gorm.DB) gin.H {
+// GetNetworth reports the amounts in the currency of the converter, the
+// default currency if the converter is nil. The timeline is projected
+// for the given number of months if forecastMonths is positive.
+func GetNetworth(db *gorm.DB, converter *service.Converter, forecastMonths int, returns map[string]float64, deflator *service.Deflator) gin.H {
 	postings := query.Init(db).Like("Assets:%", "Income:CapitalGains:%", "Liabilities:%").UntilToday().All()
 
-	postings = service.PopulateMarketPrice(db, postings)
-	networthTimeline := computeNetworthTimeline(db, postings, false)
+	postings = converter.ConvertPostings(service.PopulateMarketPrice(db, postings))
+	networthTimeline := computeNetworthTimeline(db, postings, false, converter)
 	xirr := service.XIRR(db, postings)
-	return gin.H{"networthTimeline": networthTimeline, "xirr": xirr}
+	result := gin.H{"networthTimeline": networthTimeline, "xirr": xirr, "currency": reportingCurrency(converter)}
+
+	forecastTimeline := []Networth{}
+	if forecastMonths > 0 {
+		last := Networth{}
+		if len(networthTimeline) > 0 {
+			last = networthTimeline[len(networthTimeline)-1]
+		}
+		forecastTimeline, result["expectedReturns"] = forecastNetworth(db, postings, last, forecastMonths, returns, converter)
+		result["forecastTimeline"] = forecastTimeline
+	}
+
+	if deflator != nil {
+		// the forecast continues the cumulative amounts of the timeline
+		deflated := deflateNetworths(append(networthTimeline, forecastTimeline...), deflator)
+		result["networthTimeline"] = deflated[:len(networthTimeline)]
+		if forecastMonths > 0 {
+			result["forecastTimeline"] = deflated[len(networthTimeline):]
+		}
+		result["realXirr"] = deflator.XIRR(db, postings)
+	}
+	return result
+}
+
+func reportingCurrency(converter *service.Converter) string {
+	if converter == nil {
+		return config.DefaultCurrency()
+	}
+	return converter.Currency()
 }
 
 func GetCurrentNetworth(db *gorm.DB) gin.H {
@@ -70,7 +102,7 @@ func computeNetworth(db *gorm.DB, postings []posting.Posting) Networth {
 				withdrawal = withdrawal.Add(p.Amount.Neg())
 			}
 
-			balance = balance.Add(service.GetMarketPrice(db, p, now))
+			balance = balance.Add(p.MarketAmount)
 		}
 	}
 
@@ -88,7 +120,9 @@ func computeNetworth(db *gorm.DB, postings []posting.Posting) Networth {
 	return networth
 }
 
-func computeNetworthTimeline(db *gorm.DB, postings []posting.Posting, computeBalanceUnits bool) []Networth {
+// computeNetworthTimeline expects the postings to be converted with the
+// converter
+func computeNetworthTimeline(db *gorm.DB, postings []posting.Posting, computeBalanceUnits bool, converter *service.Converter) []Networth {
 	var networths []Networth
 
 	var p posting.Posting
@@ -124,7 +158,7 @@ func computeNetworthTimeline(db *gorm.DB, postings []posting.Posting, computeBal
 			}
 
 			if !isCapitalGains {
-				rs.balance = rs.balance.Add(service.GetMarketPrice(db, p, start))
+				rs.balance = rs.balance.Add(converter.MarketPrice(db, p, start))
 				rs.balanceUnits = rs.balanceUnits.Add(p.Quantity)
 			}
 
@@ -142,14 +176,19 @@ func computeNetworthTimeline(db *gorm.DB, postings []posting.Posting, computeBal
 			withdrawal = withdrawal.Add(rs.withdrawal)
 
 			if utils.IsCurrency(commodity) {
-				balance = balance.Add(rs.balance)
+				if converter != nil {
+					// the cash is revalued at the exchange rate of the day
+					balance = balance.Add(converter.Convert(rs.balanceUnits, start))
+				} else {
+					balance = balance.Add(rs.balance)
+				}
 			} else {
 				if computeBalanceUnits {
 					balanceUnits = balanceUnits.Add(rs.balanceUnits)
 				}
 				price := service.GetUnitPrice(db, commodity, start)
 				if !price.Value.Equal(decimal.Zero) {
-					balance = balance.Add(rs.balanceUnits.Mul(price.Value))
+					balance = balance.Add(converter.Convert(rs.balanceUnits.Mul(price.Value), start))
 				} else {
 					balance = balance.Add(rs.balance)
 				}
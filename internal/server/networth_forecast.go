@@ -0,0 +1,196 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/query"
+	"github.com/ananthakumaran/paisa/internal/server/goal"
+	"github.com/ananthakumaran/paisa/internal/service"
+	"github.com/ananthakumaran/paisa/internal/utils"
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// maxForecastMonths limits the forecast horizon
+const maxForecastMonths = 120
+
+// liabilitiesClass and checkingClass hold the balance of the
+// liabilities and the checking accounts, which don't earn any return
+// unless overridden
+const (
+	liabilitiesClass = "Liabilities"
+	checkingClass    = "Checking"
+)
+
+var forecastRegex = regexp.MustCompile(`^([0-9]+)([my])$`)
+
+type ExpectedReturn struct {
+	AssetClass string          `json:"asset_class"`
+	Balance    decimal.Decimal `json:"balance"`
+	Return     float64         `json:"return"`
+}
+
+// ParseForecast parses the forecast horizon like 24m or 2y into months
+func ParseForecast(value string) (int, error) {
+	match := forecastRegex.FindStringSubmatch(value)
+	if match == nil {
+		return 0, errors.New(fmt.Sprintf("Invalid forecast %s, expected the number of months or years like 24m or 2y", value))
+	}
+
+	months, _ := strconv.Atoi(match[1])
+	if match[2] == "y" {
+		months *= 12
+	}
+
+	if months < 1 || months > maxForecastMonths {
+		return 0, errors.New(fmt.Sprintf("Forecast should be between 1 and %d months", maxForecastMonths))
+	}
+	return months, nil
+}
+
+// forecastNetworth projects the networth month by month from the last
+// point of the timeline. The postings are the converted actual postings
+// of the networth. The returns override the expected annual return of
+// the asset classes, in percentage.
+func forecastNetworth(db *gorm.DB, postings []posting.Posting, last Networth, months int, returns map[string]float64, converter *service.Converter) ([]Networth, []ExpectedReturn) {
+	now := utils.EndOfToday()
+	end := now.AddDate(0, months, 0)
+
+	balances := make(map[string]decimal.Decimal)
+	for _, p := range postings {
+		if service.IsCapitalGains(p) {
+			continue
+		}
+		class := forecastClassOf(p.Account)
+		balances[class] = balances[class].Add(p.MarketAmount)
+	}
+
+	investments := lo.Filter(postings, func(p posting.Posting, _ int) bool {
+		return strings.HasPrefix(p.Account, "Assets:") && forecastClassOf(p.Account) != checkingClass
+	})
+	expected := lo.Associate(goal.EstimateAssetClasses(db, investments, now), func(class goal.AssetClass) (string, float64) {
+		return class.Name, class.Return
+	})
+	for class, value := range returns {
+		expected[class] = value
+	}
+
+	expectedReturns := []ExpectedReturn{}
+	for class, balance := range balances {
+		if class == liabilitiesClass {
+			continue
+		}
+		expectedReturns = append(expectedReturns, ExpectedReturn{AssetClass: class, Balance: balance, Return: expected[class]})
+	}
+	sort.Slice(expectedReturns, func(i, j int) bool { return expectedReturns[i].AssetClass < expectedReturns[j].AssetClass })
+
+	forecasts := query.Init(db).Like("Assets:%", "Liabilities:%").Forecast().All()
+	forecastedAccounts := lo.Uniq(lo.Map(query.Init(db).Like("Income:%", "Expenses:%").Forecast().All(), func(p posting.Posting, _ int) string { return p.Account }))
+	flows := append(forecasts, recurringFlows(ComputeRecurringTransactions(query.Init(db).All()), forecastedAccounts, now, end)...)
+	flows = converter.ConvertPostings(flows)
+
+	return projectNetworth(last, balances, expected, flows, now, months), expectedReturns
+}
+
+func forecastClassOf(account string) string {
+	if strings.HasPrefix(account, "Liabilities:") {
+		return liabilitiesClass
+	}
+	if utils.IsCheckingAccount(account) {
+		return checkingClass
+	}
+	return goal.AssetClassOf(account)
+}
+
+// recurringFlows repeats the asset and liability postings of the last
+// transaction of every recurring sequence at its interval. The
+// sequences with an income or expense account which already has
+// forecast transactions are skipped to avoid counting them twice.
+func recurringFlows(sequences []TransactionSequence, forecastedAccounts []string, now time.Time, end time.Time) []posting.Posting {
+	flows := []posting.Posting{}
+	for _, sequence := range sequences {
+		latest := sequence.Transactions[0]
+		forecasted := lo.SomeBy(latest.Postings, func(p posting.Posting) bool { return lo.Contains(forecastedAccounts, p.Account) })
+		if forecasted || sequence.Interval <= 0 {
+			continue
+		}
+
+		for date := latest.Date.AddDate(0, 0, sequence.Interval); !date.After(end); date = date.AddDate(0, 0, sequence.Interval) {
+			if !date.After(now) {
+				continue
+			}
+
+			for _, p := range latest.Postings {
+				if strings.HasPrefix(p.Account, "Assets:") || strings.HasPrefix(p.Account, "Liabilities:") {
+					p.Date = date
+					p.Forecast = true
+					flows = append(flows, p)
+				}
+			}
+		}
+	}
+	return flows
+}
+
+// projectNetworth grows the balance of every asset class at its
+// expected return compounded monthly and adds the flows of the month.
+// Like the timeline, the positive flows count as investment and the
+// negative ones as withdrawal.
+func projectNetworth(last Networth, balances map[string]decimal.Decimal, returns map[string]float64, flows []posting.Posting, now time.Time, months int) []Networth {
+	balances = lo.Assign(balances)
+	investment := last.InvestmentAmount
+	withdrawal := last.WithdrawalAmount
+
+	sort.SliceStable(flows, func(i, j int) bool { return flows[i].Date.Before(flows[j].Date) })
+	networths := []Networth{}
+	for month := 1; month <= months; month++ {
+		date := now.AddDate(0, month, 0)
+		for class, balance := range balances {
+			if class == liabilitiesClass || !balance.IsPositive() {
+				continue
+			}
+			growth := math.Pow(1+returns[class]/100, 1.0/12)
+			balances[class] = balance.Mul(decimal.NewFromFloat(growth))
+		}
+
+		for len(flows) > 0 && !flows[0].Date.After(date) {
+			p := flows[0]
+			flows = flows[1:]
+			if !p.Date.After(now) {
+				continue
+			}
+
+			class := forecastClassOf(p.Account)
+			balances[class] = balances[class].Add(p.Amount)
+			if p.Amount.IsPositive() {
+				investment = investment.Add(p.Amount)
+			} else {
+				withdrawal = withdrawal.Add(p.Amount.Neg())
+			}
+		}
+
+		balance := decimal.Zero
+		for _, b := range balances {
+			balance = balance.Add(b)
+		}
+
+		networths = append(networths, Networth{
+			Date:                date,
+			InvestmentAmount:    investment,
+			WithdrawalAmount:    withdrawal,
+			GainAmount:          balance.Add(withdrawal).Sub(investment),
+			BalanceAmount:       balance,
+			NetInvestmentAmount: investment.Sub(withdrawal),
+		})
+	}
+	return networths
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/model/transaction"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseForecast(t *testing.T) {
+	months, err := ParseForecast("24m")
+	assert.NoError(t, err)
+	assert.Equal(t, 24, months)
+
+	months, err = ParseForecast("2y")
+	assert.NoError(t, err)
+	assert.Equal(t, 24, months)
+
+	for _, value := range []string{"", "24", "0m", "-1m", "11y", "1.5y"} {
+		_, err = ParseForecast(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestProjectNetworth(t *testing.T) {
+	now := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	last := Networth{InvestmentAmount: decimal.NewFromInt(2000), WithdrawalAmount: decimal.Zero}
+	balances := map[string]decimal.Decimal{
+		"Equity":         decimal.NewFromInt(1000),
+		"Checking":       decimal.NewFromInt(1000),
+		liabilitiesClass: decimal.NewFromInt(-500),
+	}
+	returns := map[string]float64{"Equity": 12.6825}
+	flows := []posting.Posting{
+		{Date: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), Account: "Assets:Checking", Amount: decimal.NewFromInt(5000)},
+		{Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Account: "Assets:Checking", Amount: decimal.NewFromInt(-100)},
+		{Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Account: "Liabilities:Home", Amount: decimal.NewFromInt(100)},
+		{Date: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), Account: "Assets:Checking", Amount: decimal.NewFromInt(300)},
+	}
+
+	networths := projectNetworth(last, balances, returns, flows, now, 2)
+	assert.Len(t, networths, 2)
+
+	assert.Equal(t, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), networths[0].Date)
+	assert.InDelta(t, 1510, networths[0].BalanceAmount.InexactFloat64(), 0.01)
+	assert.Equal(t, "2100", networths[0].InvestmentAmount.String())
+	assert.Equal(t, "100", networths[0].WithdrawalAmount.String())
+
+	assert.InDelta(t, 1820.10, networths[1].BalanceAmount.InexactFloat64(), 0.01)
+	assert.Equal(t, "2400", networths[1].InvestmentAmount.String())
+	assert.InDelta(t, 1820.10+100-2400, networths[1].GainAmount.InexactFloat64(), 0.01)
+	assert.Equal(t, "1000", balances["Equity"].String())
+}
+
+func TestRecurringFlows(t *testing.T) {
+	now := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	end := now.AddDate(0, 2, 0)
+	rent := transaction.Transaction{
+		Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Postings: []posting.Posting{
+			{Account: "Expenses:Rent", Amount: decimal.NewFromInt(100)},
+			{Account: "Assets:Checking", Amount: decimal.NewFromInt(-100)},
+		},
+	}
+	salary := transaction.Transaction{
+		Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Postings: []posting.Posting{
+			{Account: "Income:Salary", Amount: decimal.NewFromInt(-1000)},
+			{Account: "Assets:Checking", Amount: decimal.NewFromInt(1000)},
+		},
+	}
+	sequences := []TransactionSequence{
+		{Transactions: []transaction.Transaction{rent}, Interval: 30},
+		{Transactions: []transaction.Transaction{salary}, Interval: 30},
+	}
+
+	flows := recurringFlows(sequences, []string{"Income:Salary"}, now, end)
+	assert.Len(t, flows, 2)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), flows[0].Date)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), flows[1].Date)
+	assert.Equal(t, "Assets:Checking", flows[0].Account)
+	assert.Equal(t, "-100", flows[0].Amount.String())
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ananthakumaran/paisa/internal/cache"
 	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/ananthakumaran/paisa/internal/model"
+	"github.com/ananthakumaran/paisa/internal/model/journal_file"
 	"github.com/ananthakumaran/paisa/internal/model/posting"
 	"github.com/ananthakumaran/paisa/internal/model/price"
 	"github.com/ananthakumaran/paisa/internal/scraper"
@@ -60,6 +61,12 @@ func ClearPriceCache(db *gorm.DB) gin.H {
 
 	cache.Clear()
 
+	// the journal prices were deleted as well
+	err = journal_file.DeleteAll(db)
+	if err != nil {
+		return gin.H{"success": false, "message": err.Error()}
+	}
+
 	message, err := model.SyncJournal(db)
 	if err != nil {
 		return gin.H{"success": false, "message": message}
@@ -69,13 +76,19 @@ func ClearPriceCache(db *gorm.DB) gin.H {
 }
 
 func ClearPriceProviderCache(db *gorm.DB, code string) gin.H {
-	provider := scraper.GetProviderByCode(code)
+	provider, err := scraper.GetProviderByCode(code)
+	if err != nil {
+		return gin.H{"success": false, "message": err.Error()}
+	}
 	provider.ClearCache(db)
 	return gin.H{}
 }
 
 func GetPriceAutoCompletions(db *gorm.DB, request AutoCompleteRequest) gin.H {
-	provider := scraper.GetProviderByCode(request.Provider)
+	provider, err := scraper.GetProviderByCode(request.Provider)
+	if err != nil {
+		return gin.H{"completions": []price.AutoCompleteItem{}}
+	}
 	completions := provider.AutoComplete(db, request.Field, request.Filters)
 
 	completions = lo.Filter(completions, func(completion price.AutoCompleteItem, _ int) bool {
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/accounting"
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/model/statement"
+	"github.com/ananthakumaran/paisa/internal/query"
+	"github.com/ananthakumaran/paisa/internal/utils"
+)
+
+// maxOutstandingCandidates limits the number of uncleared postings
+// searched for the combination that explains the difference
+const maxOutstandingCandidates = 20
+
+type Reconciliation struct {
+	Statement      statement.Statement `json:"statement"`
+	JournalBalance decimal.Decimal     `json:"journal_balance"`
+	ClearedBalance decimal.Decimal     `json:"cleared_balance"`
+	Difference     decimal.Decimal     `json:"difference"`
+	Uncleared      []posting.Posting   `json:"uncleared"`
+	Outstanding    []uint              `json:"outstanding"`
+}
+
+func GetReconciliations(db *gorm.DB) gin.H {
+	statements, err := statement.All(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	reconciliations := lo.Map(statements, func(s statement.Statement, _ int) Reconciliation {
+		return reconcile(db, s, previousStatementDate(statements, s))
+	})
+	return gin.H{"reconciliations": reconciliations}
+}
+
+// previousStatementDate returns the date of the latest statement of the
+// same account before the statement, zero if there is none
+func previousStatementDate(statements []statement.Statement, s statement.Statement) time.Time {
+	previous := time.Time{}
+	for _, other := range statements {
+		if other.Account == s.Account && other.Date.Before(s.Date) && other.Date.After(previous) {
+			previous = other.Date
+		}
+	}
+	return previous
+}
+
+// reconcile compares the statement balance with the journal balance as
+// of the end of the statement date. The uncleared postings after the
+// previous statement, which are not yet in the statement, explain the
+// difference.
+func reconcile(db *gorm.DB, s statement.Statement, previous time.Time) Reconciliation {
+	postings := query.Init(db).Where("account = ? and date <= ?", s.Account, utils.EndOfDay(s.Date)).All()
+
+	cleared := lo.Filter(postings, func(p posting.Posting, _ int) bool { return p.Status == "cleared" })
+	uncleared := lo.Filter(postings, func(p posting.Posting, _ int) bool {
+		return p.Status != "cleared" && p.Date.After(utils.EndOfDay(previous))
+	})
+	journalBalance := accounting.CurrentBalanceOn(db, postings, s.Date)
+	difference := journalBalance.Sub(s.Balance)
+
+	return Reconciliation{
+		Statement:      s,
+		JournalBalance: journalBalance,
+		ClearedBalance: accounting.CurrentBalanceOn(db, cleared, s.Date),
+		Difference:     difference,
+		Uncleared:      uncleared,
+		Outstanding: lo.Map(explainDifference(uncleared, difference), func(p posting.Posting, _ int) uint {
+			return p.ID
+		}),
+	}
+}
+
+// explainDifference finds the smallest set of postings whose amounts
+// add up to the difference, nil if there is none
+func explainDifference(postings []posting.Posting, difference decimal.Decimal) []posting.Posting {
+	if difference.IsZero() {
+		return []posting.Posting{}
+	}
+
+	if accounting.CostSum(postings).Equal(difference) {
+		return postings
+	}
+
+	if len(postings) > maxOutstandingCandidates {
+		return nil
+	}
+
+	var best []int
+	var search func(start int, chosen []int, sum decimal.Decimal)
+	search = func(start int, chosen []int, sum decimal.Decimal) {
+		if best != nil && len(chosen) >= len(best) {
+			return
+		}
+
+		if len(chosen) > 0 && sum.Equal(difference) {
+			best = slices.Clone(chosen)
+			return
+		}
+
+		for i := start; i < len(postings); i++ {
+			search(i+1, append(chosen, i), sum.Add(postings[i].Amount))
+		}
+	}
+	search(0, []int{}, decimal.Zero)
+
+	if best == nil {
+		return nil
+	}
+
+	return lo.Map(best, func(i int, _ int) posting.Posting { return postings[i] })
+}
+
+func SaveStatement(db *gorm.DB, s statement.Statement) gin.H {
+	if s.Account == "" || s.Date.IsZero() {
+		return gin.H{"success": false, "message": "Account and date are required"}
+	}
+
+	s.Date = time.Date(s.Date.Year(), s.Date.Month(), s.Date.Day(), 0, 0, 0, 0, s.Date.Location())
+	s, err := statement.Upsert(db, s)
+	if err != nil {
+		return gin.H{"success": false, "message": err.Error()}
+	}
+
+	statements, err := statement.All(db)
+	if err != nil {
+		return gin.H{"success": false, "message": err.Error()}
+	}
+
+	return gin.H{"success": true, "reconciliation": reconcile(db, s, previousStatementDate(statements, s))}
+}
+
+func DeleteStatement(db *gorm.DB, id uint) gin.H {
+	err := statement.Delete(db, id)
+	if err != nil {
+		return gin.H{"success": false, "message": err.Error()}
+	}
+	return gin.H{"success": true}
+}
+
+// ClearPostings marks the postings as cleared in the journal by adding
+// the * flag to the posting lines. The files are saved through the
+// editor, so the journal is validated and backed up.
+func ClearPostings(db *gorm.DB, ids []uint) gin.H {
+	var postings []posting.Posting
+	err := db.Where("id in ? and status != ?", ids, "cleared").Find(&postings).Error
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dir := filepath.Dir(config.GetJournalPath())
+	byFile := lo.GroupBy(postings, func(p posting.Posting) string { return p.FileName })
+	files := lo.Keys(byFile)
+	sort.Strings(files)
+
+	for _, name := range files {
+		filePath, err := utils.BuildSubPath(dir, name)
+		if err != nil {
+			return gin.H{"saved": false, "message": "Invalid file name"}
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			log.Warn(err)
+			return gin.H{"saved": false, "message": "Failed to read file"}
+		}
+
+		lines := strings.Split(string(content), "\n")
+		for _, p := range byFile[name] {
+			err := clearPosting(lines, int(p.TransactionBeginLine), int(p.TransactionEndLine), p.Account)
+			if err != nil {
+				return gin.H{"saved": false, "message": fmt.Sprintf("%s:%d %s", name, p.TransactionBeginLine, err.Error())}
+			}
+		}
+
+		result := SaveFile(db, LedgerFile{Name: name, Content: strings.Join(lines, "\n")})
+		if saved, _ := result["saved"].(bool); !saved {
+			return result
+		}
+	}
+
+	return gin.H{"saved": true, "cleared": len(postings)}
+}
+
+// clearPosting flags the first uncleared line of the account in the
+// transaction found within the line range
+func clearPosting(lines []string, begin int, end int, account string) error {
+	begin, end = max(begin, 1), min(end, len(lines))
+
+	header := -1
+	for i := begin; i <= end; i++ {
+		line := lines[i-1]
+		if line != "" && unicode.IsDigit(rune(line[0])) {
+			header = i
+			break
+		}
+	}
+
+	if header == -1 {
+		return errors.New("Transaction not found")
+	}
+
+	for i := header + 1; i <= len(lines); i++ {
+		line := lines[i-1]
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || trimmed == line {
+			break
+		}
+
+		flag := ""
+		if trimmed[0] == '*' || trimmed[0] == '!' {
+			flag, trimmed = trimmed[:1], strings.TrimLeft(trimmed[1:], " \t")
+		}
+
+		if flag == "*" || !isPostingOf(trimmed, account) {
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i-1] = indent + "* " + trimmed
+		return nil
+	}
+
+	return errors.New(fmt.Sprintf("Uncleared posting of %s not found", account))
+}
+
+// isPostingOf checks whether the posting line belongs to the account,
+// the account is separated from the amount by at least two spaces or
+// a tab
+func isPostingOf(line string, account string) bool {
+	if !strings.HasPrefix(line, account) {
+		return false
+	}
+
+	rest := line[len(account):]
+	return rest == "" || strings.HasPrefix(rest, "  ") || strings.HasPrefix(rest, "\t") || strings.HasPrefix(rest, " ;")
+}
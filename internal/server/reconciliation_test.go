@@ -0,0 +1,83 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/model/statement"
+)
+
+func unclearedPosting(id uint, amount float64) posting.Posting {
+	return posting.Posting{ID: id, Account: "Assets:Checking", Amount: decimal.NewFromFloat(amount), Status: "unmarked"}
+}
+
+func TestExplainDifference(t *testing.T) {
+	postings := []posting.Posting{
+		unclearedPosting(1, -500),
+		unclearedPosting(2, -1200),
+		unclearedPosting(3, 3000),
+		unclearedPosting(4, -700),
+	}
+
+	ids := func(ps []posting.Posting) []uint {
+		return lo.Map(ps, func(p posting.Posting, _ int) uint { return p.ID })
+	}
+
+	assert.Equal(t, []uint{2}, ids(explainDifference(postings, decimal.NewFromFloat(-1200))))
+	assert.Equal(t, []uint{3, 4}, ids(explainDifference(postings, decimal.NewFromFloat(2300))))
+	assert.Equal(t, []uint{1, 2, 3, 4}, ids(explainDifference(postings, decimal.NewFromFloat(600))))
+	assert.Nil(t, explainDifference(postings, decimal.NewFromFloat(-100)))
+	assert.Empty(t, explainDifference(postings, decimal.Zero))
+}
+
+func TestPreviousStatementDate(t *testing.T) {
+	statements := []statement.Statement{
+		{Account: "Assets:Checking", Date: time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{Account: "Assets:Checking", Date: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{Account: "Assets:Checking", Date: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{Account: "Assets:Savings", Date: time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	assert.Equal(t, statements[1].Date, previousStatementDate(statements, statements[0]))
+	assert.Equal(t, statements[2].Date, previousStatementDate(statements, statements[1]))
+	assert.True(t, previousStatementDate(statements, statements[2]).IsZero())
+	assert.True(t, previousStatementDate(statements, statements[3]).IsZero())
+}
+
+func TestClearPosting(t *testing.T) {
+	journal := `2023/01/02 Salary
+    Assets:Checking    10,000 INR
+    Income:Salary
+
+2023/01/03 ! Rent
+    ; monthly
+    ! Assets:Checking:Savings    -100 INR
+    ! Assets:Checking    -5000 INR ; paid
+    Expenses:Rent`
+
+	lines := strings.Split(journal, "\n")
+	assert.NoError(t, clearPosting(lines, 1, 3, "Assets:Checking"))
+	assert.Equal(t, "    * Assets:Checking    10,000 INR", lines[1])
+	assert.Error(t, clearPosting(lines, 1, 3, "Assets:Checking"))
+
+	assert.NoError(t, clearPosting(lines, 4, 9, "Assets:Checking"))
+	assert.Equal(t, "    ! Assets:Checking:Savings    -100 INR", lines[6])
+	assert.Equal(t, "    * Assets:Checking    -5000 INR ; paid", lines[7])
+	assert.Equal(t, "2023/01/03 ! Rent", lines[4])
+
+	assert.Error(t, clearPosting(lines, 4, 9, "Assets:Savings"))
+}
+
+func TestIsPostingOf(t *testing.T) {
+	assert.True(t, isPostingOf("Assets:Checking", "Assets:Checking"))
+	assert.True(t, isPostingOf("Assets:Checking\t-10 INR", "Assets:Checking"))
+	assert.True(t, isPostingOf("Assets:Bank Account  -10 INR", "Assets:Bank Account"))
+	assert.False(t, isPostingOf("Assets:Checking:HDFC  -10 INR", "Assets:Checking"))
+	assert.False(t, isPostingOf("Assets:Bank Account  -10 INR", "Assets:Bank"))
+}
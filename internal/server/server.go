@@ -5,22 +5,28 @@ import (
 	"crypto/subtle"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ananthakumaran/paisa/internal/accounting"
 	"github.com/ananthakumaran/paisa/internal/background"
+	"github.com/ananthakumaran/paisa/internal/background/kite"
 	"github.com/ananthakumaran/paisa/internal/config"
 	"github.com/ananthakumaran/paisa/internal/generator"
 	"github.com/ananthakumaran/paisa/internal/ledger"
 	"github.com/ananthakumaran/paisa/internal/model"
+	"github.com/ananthakumaran/paisa/internal/model/statement"
 	"github.com/ananthakumaran/paisa/internal/model/template"
 	"github.com/ananthakumaran/paisa/internal/prediction"
 	"github.com/ananthakumaran/paisa/internal/server/assets"
 	"github.com/ananthakumaran/paisa/internal/server/goal"
 	"github.com/ananthakumaran/paisa/internal/server/liabilities"
 	"github.com/ananthakumaran/paisa/internal/server/stocks"
+	"github.com/ananthakumaran/paisa/internal/service"
 	"github.com/ananthakumaran/paisa/internal/utils"
 	"github.com/ananthakumaran/paisa/web"
 
@@ -37,7 +43,7 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 
 	router := gin.New()
 	if enableCompression {
-		router.Use(gzip.Gzip(gzip.DefaultCompression))
+		router.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/api/events"})))
 	}
 
 	router.Use(Logger(log.StandardLogger()), gin.Recovery())
@@ -56,6 +62,10 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 		c.JSON(200, gin.H{"success": true})
 	})
 
+	router.GET("/api/events", func(c *gin.Context) {
+		StreamEvents(c)
+	})
+
 	router.GET("/api/config", func(c *gin.Context) {
 		var now *time.Time
 		if utils.IsNowDefined() {
@@ -118,7 +128,42 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 	})
 
 	router.GET("/api/networth", func(c *gin.Context) {
-		c.JSON(200, GetNetworth(db))
+		converter, err := service.NewConverter(db, c.Query("currency"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		forecastMonths := 0
+		if forecast := c.Query("forecast"); forecast != "" {
+			forecastMonths, err = ParseForecast(forecast)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		returns := make(map[string]float64)
+		for class, value := range c.QueryMap("return") {
+			returns[class], err = strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(returns[class]) || math.IsInf(returns[class], 0) || returns[class] <= -100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid return %s for %s", value, class)})
+				return
+			}
+		}
+
+		deflator, err := deflatorOf(db, c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// the consumer price index is of the default currency
+		if deflator != nil && converter != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Inflation adjusted amounts are not available in a reporting currency"})
+			return
+		}
+		c.JSON(200, GetNetworth(db, converter, forecastMonths, returns, deflator))
 	})
 
 	router.GET("/api/assets/balance", func(c *gin.Context) {
@@ -129,17 +174,32 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 		c.JSON(200, GetInvestment(db))
 	})
 	router.GET("/api/gain", func(c *gin.Context) {
-		c.JSON(200, GetGain(db))
+		converter, err := service.NewConverter(db, c.Query("currency"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, GetGain(db, converter))
 	})
 	router.GET("/api/gain/:account", func(c *gin.Context) {
 		account := c.Param("account")
-		c.JSON(200, GetAccountGain(db, account))
+		converter, err := service.NewConverter(db, c.Query("currency"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, GetAccountGain(db, account, converter))
 	})
 	router.GET("/api/income", func(c *gin.Context) {
 		c.JSON(200, GetIncome(db))
 	})
 	router.GET("/api/expense", func(c *gin.Context) {
-		c.JSON(200, GetExpense(db))
+		deflator, err := deflatorOf(db, c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, GetExpense(db, deflator))
 	})
 
 	router.GET("/api/budget", func(c *gin.Context) {
@@ -150,7 +210,12 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 		c.JSON(200, GetCashFlow(db))
 	})
 	router.GET("/api/income_statement", func(c *gin.Context) {
-		c.JSON(200, GetIncomeStatement(db))
+		deflator, err := deflatorOf(db, c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, GetIncomeStatement(db, deflator))
 	})
 	router.GET("/api/recurring", func(c *gin.Context) {
 		c.JSON(200, GetRecurringTransactions(db))
@@ -220,10 +285,97 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 		c.JSON(200, GetDiagnosis(db))
 	})
 
+	router.POST("/api/diagnosis/fix", func(c *gin.Context) {
+		if config.GetConfig().Readonly {
+			c.JSON(200, gin.H{"saved": false, "message": "Readonly mode"})
+			return
+		}
+
+		var fix Fix
+		if err := c.ShouldBindJSON(&fix); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, ApplyFix(db, fix))
+	})
+
+	router.GET("/api/reconciliation", func(c *gin.Context) {
+		c.JSON(200, GetReconciliations(db))
+	})
+
+	router.POST("/api/reconciliation/statement", func(c *gin.Context) {
+		if config.GetConfig().Readonly {
+			c.JSON(200, gin.H{"success": false, "message": "Readonly mode"})
+			return
+		}
+
+		var s statement.Statement
+		if err := c.ShouldBindJSON(&s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, SaveStatement(db, s))
+	})
+
+	router.POST("/api/reconciliation/statement/delete", func(c *gin.Context) {
+		if config.GetConfig().Readonly {
+			c.JSON(200, gin.H{"success": false, "message": "Readonly mode"})
+			return
+		}
+
+		var s statement.Statement
+		if err := c.ShouldBindJSON(&s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, DeleteStatement(db, s.ID))
+	})
+
+	router.POST("/api/reconciliation/clear", func(c *gin.Context) {
+		if config.GetConfig().Readonly {
+			c.JSON(200, gin.H{"saved": false, "message": "Readonly mode"})
+			return
+		}
+
+		var request struct {
+			IDs []uint `json:"ids"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, ClearPostings(db, request.IDs))
+	})
+
 	router.GET("/api/liabilities/interest", func(c *gin.Context) {
 		c.JSON(200, liabilities.GetInterest(db))
 	})
 
+	router.POST("/api/liabilities/planner", func(c *gin.Context) {
+		var request liabilities.PlannerRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		result, err := liabilities.GetPlanner(db, request)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, result)
+	})
+
+	router.GET("/api/liabilities/loans", func(c *gin.Context) {
+		c.JSON(200, liabilities.GetLoans(db))
+	})
+
 	router.GET("/api/liabilities/balance", func(c *gin.Context) {
 		c.JSON(200, liabilities.GetBalance(db))
 	})
@@ -377,11 +529,38 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 	})
 
 	router.GET("/api/goals", func(c *gin.Context) {
-		c.JSON(200, gin.H{"goals": goal.GetGoalSummaries(db)})
+		deflator, err := deflatorOf(db, c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"goals": goal.GetGoalSummaries(db, deflator)})
 	})
 
 	router.GET("/api/goals/:type/:name", func(c *gin.Context) {
-		c.JSON(200, goal.GetGoalDetails(db, c.Param("type"), c.Param("name")))
+		deflator, err := deflatorOf(db, c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, goal.GetGoalDetails(db, c.Param("type"), c.Param("name"), deflator))
+	})
+
+	router.POST("/api/goals/retirement/:name/simulate", func(c *gin.Context) {
+		var request goal.SimulationRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		result, err := goal.GetRetirementSimulation(db, c.Param("name"), request)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, result)
 	})
 
 	router.GET("/api/credit_cards", func(c *gin.Context) {
@@ -431,6 +610,19 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 		c.JSON(200, result)
 	})
 
+	router.GET("/api/kite/accounts", func(c *gin.Context) {
+		c.JSON(200, GetKiteAccounts(db))
+	})
+
+	router.POST("/api/kite/accounts/:name/login", func(c *gin.Context) {
+		if config.GetConfig().Readonly {
+			c.JSON(200, gin.H{"success": false, "message": "Readonly mode"})
+			return
+		}
+
+		c.JSON(200, LoginKiteAccount(c.Param("name")))
+	})
+
 	// Kite Connect callback endpoint. This endpoint will be used only for manual login.
 	router.GET("/api/callback/kite", func(c *gin.Context) {
 		requestToken := c.Query("request_token")
@@ -439,6 +631,20 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 			return
 		}
 
+		// Logins started from the UI carry the account name as a redirect param
+		if account := c.Query("account"); account != "" {
+			err := kite.CompleteLogin(db, account, requestToken)
+			if err != nil {
+				log.Errorf("Failed to complete Kite login for account %s: %v", account, err)
+				c.Redirect(http.StatusFound, "/more/kite?error="+url.QueryEscape(err.Error()))
+				return
+			}
+
+			log.Infof("Successfully logged in to Kite account %s", account)
+			c.Redirect(http.StatusFound, "/more/kite?login="+url.QueryEscape(account))
+			return
+		}
+
 		// Store the request token in the database
 		err := storeKiteRequestToken(db, requestToken)
 		if err != nil {
@@ -447,7 +653,7 @@ func Build(db *gorm.DB, enableCompression bool) *gin.Engine {
 			return
 		}
 
-		log.Infof("Successfully stored Kite request token: %s", requestToken)
+		log.Info("Successfully stored Kite request token")
 		c.JSON(200, gin.H{"message": "Login successful! Request token stored."})
 	})
 
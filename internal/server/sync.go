@@ -32,6 +32,8 @@ func Sync(db *gorm.DB, request SyncRequest) gin.H {
 		if err != nil {
 			return gin.H{"success": false, "message": err.Error()}
 		}
+		// the bundled index is used if the fetch fails
+		model.SyncCPI(db)
 	}
 
 	if request.Portfolios {
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/utils"
+)
+
+// Converter converts the amounts in the default currency to the
+// reporting currency using the exchange rate on the date. A nil
+// Converter leaves the amounts in the default currency.
+type Converter struct {
+	db       *gorm.DB
+	currency string
+	earliest decimal.Decimal
+}
+
+// NewConverter returns nil if the currency is empty or the default
+// currency
+func NewConverter(db *gorm.DB, currency string) (*Converter, error) {
+	if currency == "" || utils.IsCurrency(currency) {
+		return nil, nil
+	}
+
+	if !HasPrices(db, currency) {
+		return nil, fmt.Errorf("No exchange rate found for %s, add the currency as a commodity with the fx price provider", currency)
+	}
+
+	prices := GetAllPrices(db, currency)
+	return &Converter{db: db, currency: currency, earliest: prices[len(prices)-1].Value}, nil
+}
+
+func (c *Converter) Currency() string {
+	if c == nil {
+		return ""
+	}
+	return c.currency
+}
+
+// rate is the value of one unit of the reporting currency in the
+// default currency, the earliest rate is used for the dates before it
+func (c *Converter) rate(date time.Time) decimal.Decimal {
+	pc := GetUnitPrice(c.db, c.currency, date)
+	if pc.Value.IsZero() {
+		return c.earliest
+	}
+	return pc.Value
+}
+
+func (c *Converter) Convert(amount decimal.Decimal, date time.Time) decimal.Decimal {
+	if c == nil {
+		return amount
+	}
+	return amount.Div(c.rate(date))
+}
+
+// ConvertPostings converts the amount on the posting date and the
+// market amount on the current date
+func (c *Converter) ConvertPostings(ps []posting.Posting) []posting.Posting {
+	if c == nil {
+		return ps
+	}
+
+	today := utils.EndOfToday()
+	converted := make([]posting.Posting, len(ps))
+	for i, p := range ps {
+		p.Amount = c.Convert(p.Amount, p.Date)
+		p.MarketAmount = c.Convert(p.MarketAmount, today)
+		converted[i] = p
+	}
+	return converted
+}
+
+// MarketPrice is GetMarketPrice for the postings converted with
+// ConvertPostings
+func (c *Converter) MarketPrice(db *gorm.DB, p posting.Posting, date time.Time) decimal.Decimal {
+	if c == nil {
+		return GetMarketPrice(db, p, date)
+	}
+
+	if utils.IsCurrency(p.Commodity) {
+		return p.Amount
+	}
+
+	pc := GetUnitPrice(db, p.Commodity, date)
+	if !pc.Value.Equal(decimal.Zero) {
+		return c.Convert(p.Quantity.Mul(pc.Value), date)
+	}
+
+	return p.Amount
+}
+
+// HasPrices checks whether there are any prices for the commodity
+func HasPrices(db *gorm.DB, commodity string) bool {
+	pcache.Do(func() { loadPriceCache(db) })
+
+	return pcache.pricesTree[commodity] != nil && pcache.pricesTree[commodity].Len() > 0
+}
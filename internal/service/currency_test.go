@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/price"
+	"github.com/google/btree"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConverterCurrencyWithoutPostings(t *testing.T) {
+	ClearPriceCache()
+	defer ClearPriceCache()
+
+	eur := btree.New(2)
+	eur.ReplaceOrInsert(price.Price{Date: date(2023, 1, 1), CommodityType: config.Currency, CommodityName: "EUR", Value: decimal.NewFromInt(90)})
+	eur.ReplaceOrInsert(price.Price{Date: date(2023, 7, 1), CommodityType: config.Currency, CommodityName: "EUR", Value: decimal.NewFromInt(100)})
+	pcache.Do(func() {
+		pcache.pricesTree = map[string]*btree.BTree{"EUR": eur}
+		pcache.postingPricesTree = map[string]*btree.BTree{}
+	})
+
+	converter, err := NewConverter(nil, "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", converter.Currency())
+
+	assert.Equal(t, decimal.NewFromInt(10).String(), converter.Convert(decimal.NewFromInt(900), date(2023, 3, 1)).String())
+	assert.Equal(t, decimal.NewFromInt(9).String(), converter.Convert(decimal.NewFromInt(900), date(2023, 8, 1)).String())
+
+	// the earliest rate is used before the first price
+	assert.Equal(t, decimal.NewFromInt(10).String(), converter.Convert(decimal.NewFromInt(900), date(2022, 1, 1)).String())
+}
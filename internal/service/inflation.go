@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model/cpi"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/ananthakumaran/paisa/internal/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Deflator expresses the amounts in the money of the base date using
+// the consumer price index. A nil deflator leaves the amounts as is,
+// the reports take a nil deflator unless the amounts are requested
+// after adjusting for the inflation.
+type Deflator struct {
+	years  []float64
+	logs   []float64
+	baseAt float64
+}
+
+func NewDeflator(db *gorm.DB, base time.Time) (*Deflator, error) {
+	return NewDeflatorFromCPI(cpi.All(db, config.GetConfig().CPI.Country), base)
+}
+
+// NewDeflatorFromCPI builds the deflator from the annual index, in any
+// order
+func NewDeflatorFromCPI(cpis []cpi.CPI, base time.Time) (*Deflator, error) {
+	cpis = append([]cpi.CPI{}, cpis...)
+	sort.Slice(cpis, func(i, j int) bool { return cpis[i].Year < cpis[j].Year })
+
+	d := &Deflator{}
+	for _, c := range cpis {
+		if !c.Index.IsPositive() {
+			continue
+		}
+		// the annual index is the average of the year, placed at
+		// the middle of the year
+		d.years = append(d.years, float64(c.Year)+0.5)
+		d.logs = append(d.logs, math.Log(c.Index.InexactFloat64()))
+	}
+
+	if len(d.years) < 2 {
+		return nil, errors.New(fmt.Sprintf("Consumer Price Index of %s is not available", config.GetConfig().CPI.Country))
+	}
+
+	d.baseAt = d.logIndex(base)
+	return d, nil
+}
+
+// logIndex interpolates the index between the years geometrically, the
+// index before the first year and after the last year is extrapolated
+// with the inflation of the nearest year
+func (d *Deflator) logIndex(date time.Time) float64 {
+	x := float64(date.Year()) + float64(date.YearDay()-1)/float64(daysIn(date.Year()))
+	i := sort.SearchFloat64s(d.years, x) - 1
+	i = max(0, min(i, len(d.years)-2))
+
+	slope := (d.logs[i+1] - d.logs[i]) / (d.years[i+1] - d.years[i])
+	return d.logs[i] + slope*(x-d.years[i])
+}
+
+func daysIn(year int) int {
+	return time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// Factor converts the money of the date into the money of the base date
+func (d *Deflator) Factor(date time.Time) decimal.Decimal {
+	if d == nil {
+		return decimal.NewFromInt(1)
+	}
+	return decimal.NewFromFloat(math.Exp(d.baseAt - d.logIndex(date)))
+}
+
+func (d *Deflator) Deflate(amount decimal.Decimal, date time.Time) decimal.Decimal {
+	if d == nil {
+		return amount
+	}
+	return amount.Mul(d.Factor(date))
+}
+
+// DeflatePostings deflates the amount on the posting date and the
+// market amount on the current date
+func (d *Deflator) DeflatePostings(ps []posting.Posting) []posting.Posting {
+	if d == nil {
+		return ps
+	}
+
+	today := d.Factor(utils.EndOfToday())
+	deflated := make([]posting.Posting, len(ps))
+	for i, p := range ps {
+		p.Amount = d.Deflate(p.Amount, p.Date)
+		p.MarketAmount = p.MarketAmount.Mul(today)
+		deflated[i] = p
+	}
+	return deflated
+}
+
+// XIRR is the XIRR of the postings after adjusting for the inflation,
+// it doesn't depend on the base date
+func (d *Deflator) XIRR(db *gorm.DB, ps []posting.Posting) decimal.Decimal {
+	return XIRR(db, d.DeflatePostings(ps))
+}
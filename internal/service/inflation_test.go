@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ananthakumaran/paisa/internal/model/cpi"
+	"github.com/ananthakumaran/paisa/internal/model/posting"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDeflator(t *testing.T) {
+	cpis := []cpi.CPI{
+		{Year: 2021, Index: decimal.NewFromInt(110)},
+		{Year: 2020, Index: decimal.NewFromInt(100)},
+		{Year: 2022, Index: decimal.NewFromInt(121)},
+	}
+
+	deflator, err := NewDeflatorFromCPI(cpis, date(2022, 7, 2))
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 1.21, deflator.Factor(date(2020, 7, 2)).InexactFloat64(), 0.001)
+	assert.InDelta(t, 1.1, deflator.Factor(date(2021, 7, 2)).InexactFloat64(), 0.001)
+	assert.InDelta(t, 1.0, deflator.Factor(date(2022, 7, 2)).InexactFloat64(), 0.001)
+
+	// the inflation of the nearest year is used outside the index
+	assert.InDelta(t, 1/1.1, deflator.Factor(date(2023, 7, 2)).InexactFloat64(), 0.001)
+	assert.InDelta(t, 1.331, deflator.Factor(date(2019, 7, 2)).InexactFloat64(), 0.001)
+
+	// geometric interpolation between the years
+	assert.InDelta(t, 1.21/1.0488, deflator.Factor(date(2021, 1, 1)).InexactFloat64(), 0.001)
+
+	assert.InDelta(t, 110, deflator.Deflate(decimal.NewFromInt(100), date(2021, 7, 2)).InexactFloat64(), 0.1)
+}
+
+func TestDeflatorErrors(t *testing.T) {
+	_, err := NewDeflatorFromCPI([]cpi.CPI{{Year: 2020, Index: decimal.NewFromInt(100)}}, date(2022, 1, 1))
+	assert.Error(t, err)
+}
+
+func TestNilDeflator(t *testing.T) {
+	var deflator *Deflator
+	amount := decimal.NewFromInt(100)
+	assert.Equal(t, amount, deflator.Deflate(amount, date(2020, 1, 1)))
+
+	ps := []posting.Posting{{Amount: amount}}
+	assert.Equal(t, ps, deflator.DeflatePostings(ps))
+}
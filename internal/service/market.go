@@ -65,6 +65,26 @@ func loadPriceCache(db *gorm.DB) {
 			}
 		}
 	}
+
+	// the journal prices of the commodities not used in any posting,
+	// like the exchange rate of a reporting currency
+	result = db.Where("commodity_type = ?", config.Unknown).Find(&prices)
+	if result.Error != nil {
+		log.Fatal(result.Error)
+	}
+
+	for commodityName, prices := range lo.GroupBy(prices, func(p price.Price) string { return p.CommodityName }) {
+		if pcache.postingPricesTree[commodityName] != nil || pcache.pricesTree[commodityName] != nil {
+			continue
+		}
+
+		postingPricesTree := btree.New(2)
+		for _, price := range prices {
+			postingPricesTree.ReplaceOrInsert(price)
+		}
+		pcache.postingPricesTree[commodityName] = postingPricesTree
+		pcache.pricesTree[commodityName] = postingPricesTree
+	}
 }
 
 func ClearPriceCache() {
@@ -84,9 +104,11 @@ func GetUnitPrice(db *gorm.DB, commodity string, date time.Time) price.Price {
 		return pc
 	}
 
+	// the commodities not held in any posting, like the exchange rate
+	// of a reporting currency, only have the provider prices
 	pt = pcache.postingPricesTree[commodity]
 	if pt == nil {
-		log.Fatal("Price not found ", commodity)
+		return pc
 	}
 	return utils.BTreeDescendFirstLessOrEqual(pt, price.Price{Date: date})
 
@@ -95,15 +117,13 @@ func GetUnitPrice(db *gorm.DB, commodity string, date time.Time) price.Price {
 func GetAllPrices(db *gorm.DB, commodity string) []price.Price {
 	pcache.Do(func() { loadPriceCache(db) })
 
-	pt := pcache.postingPricesTree[commodity]
-	if pt == nil {
-		log.Fatal("Price not found ", commodity)
-	}
-
 	pmap := make(map[string]price.Price)
 
-	for _, price := range utils.BTreeToSlice[price.Price](pt) {
-		pmap[price.Date.String()] = price
+	pt := pcache.postingPricesTree[commodity]
+	if pt != nil {
+		for _, price := range utils.BTreeToSlice[price.Price](pt) {
+			pmap[price.Date.String()] = price
+		}
 	}
 
 	pt = pcache.pricesTree[commodity]
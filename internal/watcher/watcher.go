@@ -0,0 +1,128 @@
+package watcher
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/ananthakumaran/paisa/internal/config"
+	"github.com/ananthakumaran/paisa/internal/model"
+)
+
+// editors usually write a file in multiple steps, wait for the writes
+// to settle before syncing
+const debounce = 500 * time.Millisecond
+
+// Watch syncs the journal whenever any of the journal files change,
+// until the context is cancelled. The files are the same ones listed
+// in the editor.
+func Watch(ctx context.Context, db *gorm.DB) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	journalPath := config.GetJournalPath()
+	dir := filepath.Dir(journalPath)
+	pattern := filepath.Join(dir, "**", "*"+filepath.Ext(journalPath))
+
+	err = addDirs(watcher, dir)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Watching %s for changes", pattern)
+
+	changed := make(map[string]bool)
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					err := addDirs(watcher, event.Name)
+					if err != nil {
+						log.Warn(err)
+					}
+					continue
+				}
+			}
+
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+
+			if matched, _ := doublestar.PathMatch(pattern, event.Name); !matched {
+				continue
+			}
+
+			changed[event.Name] = true
+			timer.Reset(debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warn("Journal watcher error: ", err)
+
+		case <-timer.C:
+			files := make([]string, 0, len(changed))
+			for name := range changed {
+				if fileName, err := filepath.Rel(dir, name); err == nil {
+					files = append(files, fileName)
+				}
+			}
+			sort.Strings(files)
+			changed = make(map[string]bool)
+
+			syncJournal(db, files)
+		}
+	}
+}
+
+func syncJournal(db *gorm.DB, files []string) {
+	log.Infof("Journal files changed: %v", files)
+
+	message, err := model.SyncJournal(db)
+	if err != nil {
+		log.Warn("Failed to sync journal: ", message)
+	}
+}
+
+// addDirs watches the directory and all its subdirectories, fsnotify
+// doesn't support recursive watches.
+func addDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root && len(d.Name()) > 1 && d.Name()[0] == '.' {
+			return filepath.SkipDir
+		}
+
+		return watcher.Add(path)
+	})
+}
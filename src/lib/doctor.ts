@@ -1,9 +1,10 @@
 import * as d3 from "d3";
-import type { Issue } from "./utils";
+import type { Fix, Issue } from "./utils";
 
-export function renderIssues(issues: Issue[]) {
+export function renderIssues(issues: Issue[], applyFix: (fix: Fix) => void) {
   const id = "#d3-diagnosis";
   const root = d3.select(id);
+  root.selectAll("*").remove();
 
   const issue = root
     .selectAll("div")
@@ -23,4 +24,14 @@ export function renderIssues(issues: Issue[]) {
     .append("div")
     .attr("class", "message-body")
     .html((i) => `${i.description} <br/> <br/> ${i.details}`);
+
+  issue
+    .filter((i) => i.fix != null)
+    .select(".message-body")
+    .append("div")
+    .attr("class", "mt-3")
+    .append("button")
+    .attr("class", "button is-small")
+    .text((i) => i.fix.summary)
+    .on("click", (_event, i) => applyFix(i.fix));
 }
@@ -0,0 +1,80 @@
+import _ from "lodash";
+import { tokenKey } from "./utils";
+
+export interface ServerEvent {
+  type: string;
+  time: string;
+  data?: any;
+}
+
+const RECONNECT_DELAY = 5000;
+
+function parse(chunk: string): ServerEvent {
+  const data = chunk
+    .split("\n")
+    .filter((line) => line.startsWith("data:"))
+    .map((line) => line.slice(5).trimStart())
+    .join("\n");
+
+  if (_.isEmpty(data)) {
+    return null;
+  }
+  return JSON.parse(data);
+}
+
+// EventSource doesn't support custom headers, so the stream is read
+// with fetch to pass the auth token
+export function subscribe(handler: (event: ServerEvent) => void): () => void {
+  let stopped = false;
+  let controller: AbortController = null;
+
+  async function connect() {
+    while (!stopped) {
+      controller = new AbortController();
+      try {
+        const headers: Record<string, string> = {};
+        const token = localStorage.getItem(tokenKey);
+        if (!_.isEmpty(token)) {
+          headers["X-Auth"] = token;
+        }
+
+        const response = await fetch("/api/events", { headers, signal: controller.signal });
+        if (response.status == 401) {
+          return;
+        }
+
+        const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+        let buffer = "";
+        for (;;) {
+          const { value, done } = await reader.read();
+          if (done) {
+            break;
+          }
+
+          buffer += value;
+          let index: number;
+          while ((index = buffer.indexOf("\n\n")) >= 0) {
+            const event = parse(buffer.slice(0, index));
+            buffer = buffer.slice(index + 2);
+            if (event) {
+              handler(event);
+            }
+          }
+        }
+      } catch (e) {
+        if (stopped) {
+          return;
+        }
+      }
+
+      await new Promise((resolve) => setTimeout(resolve, RECONNECT_DELAY));
+    }
+  }
+
+  connect();
+
+  return () => {
+    stopped = true;
+    controller?.abort();
+  };
+}
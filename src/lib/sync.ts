@@ -1,11 +1,26 @@
 import * as toast from "bulma-toast";
 import { ajax } from "./utils";
 
+let syncing = 0;
+
+// the client that started the sync refreshes on its own, so it can
+// ignore the sync events published in the meantime
+export function isSyncing() {
+  return syncing > 0;
+}
+
 export async function sync(request: Record<string, any>) {
-  const { success, message } = await ajax("/api/sync", {
-    method: "POST",
-    body: JSON.stringify(request)
-  });
+  syncing++;
+  let response: { success: boolean; message: string };
+  try {
+    response = await ajax("/api/sync", {
+      method: "POST",
+      body: JSON.stringify(request)
+    });
+  } finally {
+    syncing--;
+  }
+  const { success, message } = response;
 
   if (!success) {
     toast.toast({
@@ -308,11 +308,26 @@ export interface CapitalGain {
   fy: { [key: string]: FYCapitalGain };
 }
 
+export interface KiteAccountStatus {
+  name: string;
+  state: "valid" | "expired" | "missing";
+  last_login_time: dayjs.Dayjs;
+  expiry_time: dayjs.Dayjs;
+  last_error: string;
+}
+
+export interface Fix {
+  summary: string;
+  file?: { name: string; start: number; end: number; original: string; replacement: string };
+  config?: { key: string; value: any };
+}
+
 export interface Issue {
   level: string;
   summary: string;
   description: string;
   details: string;
+  fix?: Fix;
 }
 
 export interface ScheduleALSection {
@@ -526,6 +541,9 @@ export interface GoalSummary {
   target: number;
   targetDate: string;
   priority: number;
+  projected: number;
+  requiredMonthlyContribution: number;
+  status: "" | "on-track" | "at-risk" | "off-track";
 }
 
 export interface SheetLineResult {
@@ -537,7 +555,7 @@ export interface SheetLineResult {
   align?: "left" | "right";
 }
 
-const tokenKey = "token";
+export const tokenKey = "token";
 
 type RequestOptions = RequestInit & {
   background?: boolean;
@@ -573,7 +591,10 @@ export function ajax(
 ): Promise<{ balancedPostings: BalancedPosting[] }>;
 export function ajax(route: "/api/networth"): Promise<{
   networthTimeline: Networth[];
+  forecastTimeline?: Networth[];
+  expectedReturns?: { asset_class: string; balance: number; return: number }[];
   xirr: number;
+  realXirr?: number;
 }>;
 export function ajax(route: "/api/gain"): Promise<{
   gain_breakdown: Gain[];
@@ -697,6 +718,11 @@ export function ajax(
   options?: RequestOptions
 ): Promise<{ errors: LedgerFileError[]; output: string }>;
 
+export function ajax(
+  route: "/api/diagnosis/fix",
+  options?: RequestOptions
+): Promise<{ errors?: LedgerFileError[]; saved: boolean; message?: string }>;
+
 export function ajax(
   route: "/api/editor/save",
   options?: RequestOptions
@@ -769,7 +795,10 @@ export function ajax(
 
 export function ajax(route: "/api/ping"): Promise<{ success: boolean; error?: string }>;
 
-export function ajax(route: "/api/background/tasks"): Promise<{
+export function ajax(
+  route: "/api/background/tasks",
+  options?: RequestOptions
+): Promise<{
   status: string;
   tasks: Array<{
     task_name: string;
@@ -785,6 +814,17 @@ export function ajax(
   options?: RequestOptions
 ): Promise<{ success: boolean; message?: string }>;
 
+export function ajax(route: "/api/kite/accounts"): Promise<{
+  accounts: KiteAccountStatus[];
+  error?: string;
+}>;
+
+export function ajax(
+  route: "/api/kite/accounts/:name/login",
+  options?: RequestOptions,
+  params?: Record<string, string>
+): Promise<{ success: boolean; message?: string; login_url?: string }>;
+
 export async function ajax(
   route: string,
   options?: RequestOptions,